@@ -0,0 +1,183 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"swift-deps-diagram/internal/app"
+	"swift-deps-diagram/internal/config"
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/theme"
+)
+
+// inputConfigKeys are the configuration keys the path subcommand takes; the main
+// command takes every key that names one of its flags.
+var inputConfigKeys = []string{"path", "project", "workspace", "bazel-targets", "mode", "verbose", "include-tests", "collapse-packages", "hide-external", "include", "exclude", "exclude-kind", "include-type", "exclude-type", "filter-mode"}
+
+// serveConfigKeys are the configuration keys the serve subcommand takes.
+var serveConfigKeys = append(append([]string{}, inputConfigKeys...), "addr", "cluster-by", "theme", "layout", "direction", "link-base")
+
+// subcommandConfigKeys are keys only a subcommand defines; the main command skips them.
+var subcommandConfigKeys = map[string]bool{"addr": true}
+
+// outputConfigKeys select what the main command writes. The file's values for them are
+// used only when none of them is given on the command line.
+var outputConfigKeys = []string{"format", "output", "emit", "inject"}
+
+// repeatableConfigKeys are the flags that accept a list in the configuration file.
+var repeatableConfigKeys = map[string]bool{"emit": true, "include": true, "exclude": true, "exclude-kind": true, "include-type": true, "exclude-type": true}
+
+// configPathKeys hold file paths, which the configuration file gives relative to its
+// own directory.
+var configPathKeys = map[string]bool{
+	"path":                true,
+	"project":             true,
+	"workspace":           true,
+	"output":              true,
+	"baseline":            true,
+	"structurizr-mapping": true,
+	"inject":              true,
+	"theme":               true,
+}
+
+func configError(file config.File, setting config.Setting, format string, args ...any) error {
+	return apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s:%d: %s", file.Path, setting.Line, fmt.Sprintf(format, args...)), nil)
+}
+
+// resolveConfigValue makes a path-valued setting relative to dir.
+func resolveConfigValue(dir, key, value string) string {
+	switch {
+	case key == "emit":
+		format, outputPath, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(outputPath) == "" {
+			return value
+		}
+		return format + "=" + resolveConfigValue(dir, "output", strings.TrimSpace(outputPath))
+	case !configPathKeys[key] || value == "" || filepath.IsAbs(value):
+		return value
+	case key == "theme":
+		if _, ok := theme.Preset(value); ok {
+			return value
+		}
+	}
+	return filepath.Join(dir, value)
+}
+
+// applyConfig sets every flag that was not given on the command line from the
+// configuration file found from start upward, with the named view applied. keys limits
+// the settings a subcommand takes; nil takes all of them and rejects keys that are not
+// flags. It returns the path of the file used, or "" when there is none, and the keys
+// it set.
+func applyConfig(fs *flag.FlagSet, start, view string, keys []string) (string, map[string]bool, error) {
+	path, ok := config.Find(start)
+	if !ok {
+		if view != "" {
+			return "", nil, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("--view %s requires a %s file in %s or a parent directory", view, config.FileName, start), nil)
+		}
+		return "", nil, nil
+	}
+	file, err := config.Load(path)
+	if err != nil {
+		return "", nil, err
+	}
+	settings, err := selectConfig(file, view)
+	if err != nil {
+		return "", nil, err
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	outputGiven := false
+	for _, key := range outputConfigKeys {
+		outputGiven = outputGiven || explicit[key]
+	}
+	var accepted map[string]bool
+	if keys != nil {
+		accepted = map[string]bool{}
+		for _, key := range keys {
+			accepted[key] = true
+		}
+	}
+
+	dir := filepath.Dir(path)
+	applied := map[string]bool{}
+	for _, setting := range settings {
+		if accepted != nil && !accepted[setting.Key] {
+			continue
+		}
+		if fs.Lookup(setting.Key) == nil && accepted == nil && subcommandConfigKeys[setting.Key] {
+			continue
+		}
+		if fs.Lookup(setting.Key) == nil || setting.Key == "view" {
+			return "", nil, configError(file, setting, "unknown key %q", setting.Key)
+		}
+		if explicit[setting.Key] || (outputGiven && isOutputConfigKey(setting.Key)) {
+			continue
+		}
+		if setting.List && !repeatableConfigKeys[setting.Key] {
+			return "", nil, configError(file, setting, "%s takes a single value", setting.Key)
+		}
+		for _, value := range setting.Values {
+			if err := fs.Set(setting.Key, resolveConfigValue(dir, setting.Key, value)); err != nil {
+				return "", nil, configError(file, setting, "invalid value %q for %s: %v", value, setting.Key, err)
+			}
+		}
+		applied[setting.Key] = true
+	}
+	return path, applied, nil
+}
+
+// dropUnusedConfig clears format-specific settings taken from the configuration file
+// when none of the selected formats uses them, so one file can serve every format.
+func dropUnusedConfig(opts *cliOptions, applied map[string]bool) {
+	if applied["theme"] && !opts.anyFormat(func(format string) bool { return format == "dot" || app.IsGraphvizFormat(format) }) {
+		opts.Theme = ""
+	}
+	if applied["layout"] && !opts.anyFormat(app.IsGraphvizFormat) {
+		opts.Layout = ""
+	}
+	if applied["structurizr-mapping"] && !opts.anyFormat(func(format string) bool { return format == "structurizr" }) {
+		opts.StructurizrMapping = ""
+	}
+}
+
+// selectConfig applies the named view over the top-level settings. A view that selects
+// outputs replaces all top-level output keys, as the command line does.
+func selectConfig(file config.File, view string) (config.Settings, error) {
+	settings, err := file.Select(view)
+	if err != nil || view == "" {
+		return settings, err
+	}
+	overrides := file.Views[view]
+	for _, setting := range overrides {
+		if isOutputConfigKey(setting.Key) {
+			base := config.Settings{}
+			for _, setting := range file.Settings {
+				if !isOutputConfigKey(setting.Key) {
+					base = append(base, setting)
+				}
+			}
+			return base.Merge(overrides), nil
+		}
+	}
+	return settings, nil
+}
+
+func isOutputConfigKey(key string) bool {
+	for _, outputKey := range outputConfigKeys {
+		if key == outputKey {
+			return true
+		}
+	}
+	return false
+}
+
+// reportConfig names the configuration file in use when --verbose is set.
+func reportConfig(opts cliOptions, stderr io.Writer) {
+	if opts.Verbose && opts.ConfigPath != "" {
+		fmt.Fprintf(stderr, "using configuration %s\n", opts.ConfigPath)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/app"
+	"swift-deps-diagram/internal/config"
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+func writeConfig(t *testing.T, content string) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	nested := filepath.Join(root, "ios")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, config.FileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return root, nested
+}
+
+const projectConfig = `mode: bazel
+bazel-targets: //ios/...
+include-tests: true
+format: mermaid
+output: docs/deps.mmd
+theme: dark
+views:
+  overview:
+    hide-external: true
+    collapse-packages: true
+  images:
+    emit: [svg=docs/deps.svg, dot=docs/deps.dot]
+    theme: themes/brand.json
+`
+
+func TestParseFlagsAppliesConfigDefaults(t *testing.T) {
+	root, nested := writeConfig(t, projectConfig)
+
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--path", nested, "--include-tests=false"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if opts.Mode != "bazel" || opts.BazelTargets != "//ios/..." || opts.IncludeTests || opts.Theme != "" {
+		t.Fatalf("expected config defaults with CLI override, got %#v", opts)
+	}
+	if opts.Format != "mermaid" || opts.Output != filepath.Join(root, "docs", "deps.mmd") {
+		t.Fatalf("expected output relative to the config file, got %q %q", opts.Format, opts.Output)
+	}
+	if opts.ConfigPath != filepath.Join(root, config.FileName) {
+		t.Fatalf("unexpected config path %q", opts.ConfigPath)
+	}
+}
+
+func TestParseFlagsAppliesNamedView(t *testing.T) {
+	root, nested := writeConfig(t, projectConfig)
+
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--path", nested, "--view", "overview"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if !opts.HideExternal || !opts.CollapsePackages || opts.Format != "mermaid" {
+		t.Fatalf("expected overview view over defaults, got %#v", opts)
+	}
+
+	opts, err = parseFlags([]string{"--path", nested, "--view", "images"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	want := []app.Artifact{{Format: "svg", OutputPath: filepath.Join(root, "docs", "deps.svg")}, {Format: "dot", OutputPath: filepath.Join(root, "docs", "deps.dot")}}
+	if len(opts.Emit) != 2 || opts.Emit[0] != want[0] || opts.Emit[1] != want[1] || opts.Output != "" {
+		t.Fatalf("expected the view's emit list to replace the default output, got %#v", opts)
+	}
+	if opts.Theme != filepath.Join(root, "themes", "brand.json") {
+		t.Fatalf("expected theme file relative to the config file, got %q", opts.Theme)
+	}
+}
+
+func TestParseFlagsCommandLineOutputReplacesConfigOutput(t *testing.T) {
+	_, nested := writeConfig(t, projectConfig)
+
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--path", nested, "--format", "terminal"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if opts.Format != "terminal" || opts.Output != "" {
+		t.Fatalf("expected CLI format without config output, got %q %q", opts.Format, opts.Output)
+	}
+
+	opts, err = parseFlags([]string{"--path", nested, "--format", "dot"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if opts.Theme != "dark" {
+		t.Fatalf("expected the config theme for dot output, got %q", opts.Theme)
+	}
+}
+
+func TestParseFlagsRejectsInvalidConfig(t *testing.T) {
+	cases := map[string]string{
+		"unknown key":    "colour: always\n",
+		"bad value":      "max-depth: deep\n",
+		"invalid mode":   "mode: cocoapods\n",
+		"list for flag":  "mode: [spm, xcode]\n",
+		"yaml error":     "views: [a]\n",
+		"bad emit entry": "emit: [bmp=deps.bmp]\n",
+	}
+	for name, content := range cases {
+		_, nested := writeConfig(t, content)
+		var stderr bytes.Buffer
+		if _, err := parseFlags([]string{"--path", nested}, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("%s: expected invalid args, got %v", name, err)
+		}
+	}
+
+	_, nested := writeConfig(t, projectConfig)
+	var stderr bytes.Buffer
+	_, err := parseFlags([]string{"--path", nested, "--view", "missing"}, &stderr)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) || !strings.Contains(err.Error(), "available: images, overview") {
+		t.Fatalf("expected unknown view error, got %v", err)
+	}
+
+	_, err = parseFlags([]string{"--path", t.TempDir(), "--view", "overview"}, &stderr)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected --view without a config file to fail, got %v", err)
+	}
+}
+
+func TestSubcommandsTakeOnlyTheirConfigKeys(t *testing.T) {
+	_, nested := writeConfig(t, projectConfig+"addr: 127.0.0.1:9999\n")
+
+	var stderr bytes.Buffer
+	opts, query, err := parsePathFlags([]string{"--path", nested, "--from", "App", "--to", "Core", "--view", "overview"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected path parse error: %v", err)
+	}
+	if opts.Mode != "bazel" || !opts.HideExternal || opts.Format != "terminal" || opts.Output != "" || query.From != "App" {
+		t.Fatalf("expected input keys only for path, got %#v", opts)
+	}
+
+	opts, serve, err := parseServeFlags([]string{"--path", nested}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected serve parse error: %v", err)
+	}
+	if serve.Addr != "127.0.0.1:9999" || opts.Theme != "dark" || opts.Output != "" {
+		t.Fatalf("expected serve keys from config, got %#v %#v", opts, serve)
+	}
+
+	if _, err := parseFlags([]string{"--path", nested}, &stderr); err != nil {
+		t.Fatalf("expected the main command to skip serve keys, got %v", err)
+	}
+}
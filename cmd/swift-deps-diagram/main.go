@@ -6,57 +6,251 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"strings"
 
 	"swift-deps-diagram/internal/app"
+	"swift-deps-diagram/internal/cluster"
+	"swift-deps-diagram/internal/config"
 	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/filter"
+	"swift-deps-diagram/internal/graphviz"
+	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/theme"
 )
 
 // runApp allows tests to inject a fake runner.
 var runApp = app.Run
 
 type cliOptions struct {
-	Path          string
-	ProjectPath   string
-	WorkspacePath string
-	BazelTargets  string
-	Mode          string
-	Format        string
-	Output        string
-	Verbose       bool
-	IncludeTests  bool
+	Path               string
+	ProjectPath        string
+	WorkspacePath      string
+	BazelTargets       string
+	Mode               string
+	Format             string
+	Output             string
+	Verbose            bool
+	IncludeTests       bool
+	Baseline           string
+	UpdateBaseline     bool
+	CollapsePackages   bool
+	HideExternal       bool
+	Include            listFlag
+	Exclude            listFlag
+	ExcludeKinds       listFlag
+	IncludeTypes       listFlag
+	ExcludeTypes       listFlag
+	FilterMode         string
+	ClusterBy          string
+	StructurizrMapping string
+	Unicode            bool
+	Color              string
+	MaxDepth           int
+	Invert             bool
+	Direction          string
+	LinkBase           string
+	Theme              string
+	Layout             string
+	Emit               emitFlag
+	Inject             string
+	Check              bool
+	Watch              bool
+	View               string
+	// ConfigPath is the configuration file whose settings were applied, if any.
+	ConfigPath string
 }
 
-func parseFlags(args []string, stderr io.Writer) (cliOptions, error) {
-	fs := flag.NewFlagSet("swift-deps-diagram", flag.ContinueOnError)
-	fs.SetOutput(stderr)
+// emitFlag collects repeated --emit values.
+type emitFlag []app.Artifact
 
-	opts := cliOptions{}
+func (e *emitFlag) String() string {
+	parts := make([]string, 0, len(*e))
+	for _, artifact := range *e {
+		parts = append(parts, artifact.Format+"="+artifact.OutputPath)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (e *emitFlag) Set(value string) error {
+	artifacts, err := app.ParseEmit(value)
+	if err != nil {
+		return err
+	}
+	*e = append(*e, artifacts...)
+	return nil
+}
+
+// listFlag collects the values of a repeatable flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// formats lists the output formats requested by opts.
+func (opts cliOptions) formats() []string {
+	if len(opts.Emit) == 0 {
+		return []string{opts.Format}
+	}
+	formats := make([]string, 0, len(opts.Emit))
+	for _, artifact := range opts.Emit {
+		formats = append(formats, artifact.Format)
+	}
+	return formats
+}
+
+func (opts cliOptions) anyFormat(match func(format string) bool) bool {
+	for _, format := range opts.formats() {
+		if match(format) {
+			return true
+		}
+	}
+	return false
+}
+
+func registerGraphFlags(fs *flag.FlagSet, opts *cliOptions) {
 	fs.StringVar(&opts.Path, "path", ".", "Swift package root containing Package.swift")
 	fs.StringVar(&opts.ProjectPath, "project", "", "Optional .xcodeproj path")
 	fs.StringVar(&opts.WorkspacePath, "workspace", "", "Optional .xcworkspace path")
 	fs.StringVar(&opts.BazelTargets, "bazel-targets", "", "Optional Bazel query scope expression (default //...)")
 	fs.StringVar(&opts.Mode, "mode", "auto", "Input mode: auto|spm|xcode|bazel")
-	fs.StringVar(&opts.Format, "format", "png", "Output format: mermaid|dot|png|terminal")
 	fs.StringVar(&opts.Output, "output", "", "Output file path (defaults to stdout)")
 	fs.BoolVar(&opts.Verbose, "verbose", false, "Print generation details for file outputs")
 	fs.BoolVar(&opts.IncludeTests, "include-tests", false, "Include test targets in the graph")
+	fs.BoolVar(&opts.CollapsePackages, "collapse-packages", false, "Merge all external products of a package into one package node")
+	fs.BoolVar(&opts.HideExternal, "hide-external", false, "Drop external product nodes from the graph")
+	fs.Var(&opts.Include, "include", "Keep only nodes matching this pattern: "+filter.PatternUsage+" (repeatable)")
+	fs.Var(&opts.Exclude, "exclude", "Remove nodes matching this pattern: "+filter.PatternUsage+" (repeatable)")
+	fs.Var(&opts.ExcludeKinds, "exclude-kind", "Remove nodes of this kind: "+filter.KindUsage+" (repeatable)")
+	fs.Var(&opts.IncludeTypes, "include-type", "Keep only targets of this type: "+filter.TypeUsage+" (repeatable)")
+	fs.Var(&opts.ExcludeTypes, "exclude-type", "Remove targets of this type: "+filter.TypeUsage+" (repeatable)")
+	fs.StringVar(&opts.FilterMode, "filter-mode", "drop", "How the node filters remove nodes: "+filter.ModeUsage+" (bridge keeps transitive dependencies)")
+	fs.StringVar(&opts.View, "view", "", "Named view from "+config.FileName+" to apply over its top-level settings")
+}
+
+func validateInputFlags(opts cliOptions) error {
+	switch opts.Mode {
+	case "auto", "spm", "xcode", "bazel":
+	default:
+		return apperrors.New(apperrors.KindInvalidArgs, "--mode must be one of: auto|spm|xcode|bazel", nil)
+	}
+	if opts.ProjectPath != "" && opts.WorkspacePath != "" {
+		return apperrors.New(apperrors.KindInvalidArgs, "--project and --workspace cannot be used together", nil)
+	}
+	if _, err := filter.Parse(filter.Spec{Include: opts.Include, Exclude: opts.Exclude, ExcludeKinds: opts.ExcludeKinds, IncludeTypes: opts.IncludeTypes, ExcludeTypes: opts.ExcludeTypes, Mode: opts.FilterMode}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// flagWasSet reports whether name was given explicitly on the command line.
+func flagWasSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func parseFlags(args []string, stderr io.Writer) (cliOptions, error) {
+	fs := flag.NewFlagSet("swift-deps-diagram", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	opts := cliOptions{}
+	registerGraphFlags(fs, &opts)
+	fs.StringVar(&opts.Format, "format", "png", "Output format: "+app.FormatUsage()+" (inferred from the --output extension when omitted)")
+	fs.Var(&opts.Emit, "emit", "Render several outputs from one graph: "+app.EmitUsage+" (repeatable; replaces --format/--output)")
+	fs.StringVar(&opts.Baseline, "baseline", "", "Compare the graph against a baseline snapshot JSON file")
+	fs.BoolVar(&opts.UpdateBaseline, "update-baseline", false, "Rewrite the --baseline snapshot from the current graph")
+	fs.StringVar(&opts.StructurizrMapping, "structurizr-mapping", "", "JSON file assigning targets to C4 containers for --format structurizr")
+	fs.StringVar(&opts.ClusterBy, "cluster-by", "", "Group nodes into clusters for dot/Graphviz images/mermaid/plantuml/d2: "+cluster.Usage)
+	fs.BoolVar(&opts.Unicode, "unicode", false, "Draw --format terminal trees with Unicode box-drawing characters")
+	fs.StringVar(&opts.Color, "color", "auto", "Color --format terminal trees: "+app.ColorUsage+" (auto honors NO_COLOR and TTY detection)")
+	fs.IntVar(&opts.MaxDepth, "max-depth", 0, "Limit --format terminal trees to this many levels below each root (0 = unlimited)")
+	fs.StringVar(&opts.Theme, "theme", "", "DOT and Graphviz image theme: "+theme.PresetUsage+" or a theme JSON file")
+	fs.StringVar(&opts.Layout, "layout", "", "Graphviz layout engine for image formats: "+app.LayoutUsage()+" (default dot)")
+	fs.StringVar(&opts.Direction, "direction", "TD", "Mermaid flowchart direction: "+strings.Join(render.MermaidDirections, "|"))
+	fs.StringVar(&opts.LinkBase, "link-base", "", "Repository URL or directory prefix for Mermaid click links to each node's source path")
+	fs.BoolVar(&opts.Invert, "invert", false, "Render --format terminal trees bottom-up: each leaf lists the targets that depend on it")
+	fs.StringVar(&opts.Inject, "inject", "", "Markdown file whose swift-deps-diagram:start/end region is replaced with the output (format defaults to mermaid)")
+	fs.BoolVar(&opts.Watch, "watch", false, "Keep running and regenerate the outputs whenever an input manifest changes (Ctrl-C to stop)")
+	fs.BoolVar(&opts.Check, "check", false, "With --inject, fail with exit code 3 when the injected output is out of date instead of rewriting it")
 
 	if err := fs.Parse(args); err != nil {
 		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "invalid arguments", err)
 	}
+	configPath, configured, err := applyConfig(fs, opts.Path, opts.View, nil)
+	if err != nil {
+		return cliOptions{}, err
+	}
+	opts.ConfigPath = configPath
+	if len(opts.Emit) > 0 && (flagWasSet(fs, "format") || flagWasSet(fs, "output")) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--emit cannot be combined with --format or --output", nil)
+	}
+	if opts.Inject != "" && (len(opts.Emit) > 0 || opts.Output != "") {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--inject cannot be combined with --output or --emit", nil)
+	}
+	if opts.Output != "" && !flagWasSet(fs, "format") {
+		if format, ok := app.FormatForOutput(opts.Output); ok {
+			opts.Format = format
+		}
+	}
+	if opts.Inject != "" && !flagWasSet(fs, "format") {
+		opts.Format = "mermaid"
+	}
+	dropUnusedConfig(&opts, configured)
 
-	switch opts.Format {
-	case "mermaid", "dot", "png", "terminal":
-	default:
-		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--format must be one of: mermaid|dot|png|terminal", nil)
+	if !app.IsValidFormat(opts.Format) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--format must be one of: "+app.FormatUsage(), nil)
 	}
-	switch opts.Mode {
-	case "auto", "spm", "xcode", "bazel":
+	if err := validateInputFlags(opts); err != nil {
+		return cliOptions{}, err
+	}
+	if opts.UpdateBaseline && opts.Baseline == "" {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--update-baseline requires --baseline", nil)
+	}
+	if opts.Watch && (opts.Check || opts.UpdateBaseline) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--watch cannot be combined with --check or --update-baseline", nil)
+	}
+	if opts.Check && opts.Inject == "" {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--check requires --inject", nil)
+	}
+	if opts.Inject != "" && !app.IsInjectFormat(opts.Format) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--inject requires --format "+app.InjectFormatUsage, nil)
+	}
+	if opts.StructurizrMapping != "" && !opts.anyFormat(func(format string) bool { return format == "structurizr" }) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--structurizr-mapping requires --format structurizr", nil)
+	}
+	if _, err := cluster.Parse(opts.ClusterBy); err != nil {
+		return cliOptions{}, err
+	}
+	switch opts.Color {
+	case "auto", "always", "never":
 	default:
-		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--mode must be one of: auto|spm|xcode|bazel", nil)
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--color must be one of: "+app.ColorUsage, nil)
 	}
-	if opts.ProjectPath != "" && opts.WorkspacePath != "" {
-		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--project and --workspace cannot be used together", nil)
+	if opts.Theme != "" && !opts.anyFormat(func(format string) bool { return format == "dot" || app.IsGraphvizFormat(format) }) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--theme requires --format dot or a Graphviz image format", nil)
+	}
+	if opts.Layout != "" && !graphviz.IsEngine(opts.Layout) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--layout must be one of: "+app.LayoutUsage(), nil)
+	}
+	if opts.Layout != "" && !opts.anyFormat(app.IsGraphvizFormat) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--layout requires a Graphviz image format", nil)
+	}
+	if !render.IsValidMermaidDirection(opts.Direction) {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--direction must be one of: "+strings.Join(render.MermaidDirections, "|"), nil)
+	}
+	if opts.MaxDepth < 0 {
+		return cliOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--max-depth cannot be negative", nil)
 	}
 
 	if fs.NArg() > 0 {
@@ -66,27 +260,74 @@ func parseFlags(args []string, stderr io.Writer) (cliOptions, error) {
 	return opts, nil
 }
 
+func appOptions(opts cliOptions) app.Options {
+	return app.Options{
+		PackagePath:        opts.Path,
+		ProjectPath:        opts.ProjectPath,
+		WorkspacePath:      opts.WorkspacePath,
+		BazelTargets:       opts.BazelTargets,
+		Mode:               opts.Mode,
+		Format:             opts.Format,
+		OutputPath:         opts.Output,
+		Verbose:            opts.Verbose,
+		IncludeTests:       opts.IncludeTests,
+		BaselinePath:       opts.Baseline,
+		UpdateBaseline:     opts.UpdateBaseline,
+		CollapsePackages:   opts.CollapsePackages,
+		HideExternal:       opts.HideExternal,
+		Include:            opts.Include,
+		Exclude:            opts.Exclude,
+		ExcludeKinds:       opts.ExcludeKinds,
+		IncludeTypes:       opts.IncludeTypes,
+		ExcludeTypes:       opts.ExcludeTypes,
+		FilterMode:         opts.FilterMode,
+		ClusterBy:          opts.ClusterBy,
+		StructurizrMapping: opts.StructurizrMapping,
+		Unicode:            opts.Unicode,
+		Color:              opts.Color,
+		MaxDepth:           opts.MaxDepth,
+		Invert:             opts.Invert,
+		Direction:          opts.Direction,
+		LinkBase:           opts.LinkBase,
+		Theme:              opts.Theme,
+		Layout:             opts.Layout,
+		Emit:               opts.Emit,
+		InjectPath:         opts.Inject,
+		Check:              opts.Check,
+		Watch:              opts.Watch,
+	}
+}
+
+func warnIgnoredFlags(opts cliOptions, stderr io.Writer) {
+	if opts.Mode == "spm" && (opts.ProjectPath != "" || opts.WorkspacePath != "") {
+		fmt.Fprintln(stderr, "warning: --project/--workspace are ignored when --mode=spm")
+	}
+}
+
 func execute(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "path" {
+		return executePath(args[1:], stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "serve" {
+		return executeServe(args[1:], stdout, stderr)
+	}
+
 	opts, err := parseFlags(args, stderr)
 	if err != nil {
 		fmt.Fprintln(stderr, err.Error())
 		return apperrors.ExitCode(err)
 	}
-	if opts.Mode == "spm" && (opts.ProjectPath != "" || opts.WorkspacePath != "") {
-		fmt.Fprintln(stderr, "warning: --project/--workspace are ignored when --mode=spm")
-	}
+	reportConfig(opts, stderr)
+	warnIgnoredFlags(opts, stderr)
 
-	runErr := runApp(context.Background(), app.Options{
-		PackagePath:   opts.Path,
-		ProjectPath:   opts.ProjectPath,
-		WorkspacePath: opts.WorkspacePath,
-		BazelTargets:  opts.BazelTargets,
-		Mode:          opts.Mode,
-		Format:        opts.Format,
-		OutputPath:    opts.Output,
-		Verbose:       opts.Verbose,
-		IncludeTests:  opts.IncludeTests,
-	}, stdout)
+	ctx := context.Background()
+	if opts.Watch {
+		// Ctrl-C ends a watch session normally.
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+	}
+	runErr := runApp(ctx, appOptions(opts), stdout)
 	if runErr != nil {
 		fmt.Fprintln(stderr, runErr.Error())
 		return apperrors.ExitCode(runErr)
@@ -39,6 +39,9 @@ func TestParseFlagsDefaults(t *testing.T) {
 	if opts.IncludeTests {
 		t.Fatalf("expected default include-tests false")
 	}
+	if opts.Color != "auto" || opts.Unicode || opts.MaxDepth != 0 {
+		t.Fatalf("expected default terminal style auto/ascii/unlimited, got %#v", opts)
+	}
 }
 
 func TestParseFlagsInvalidFormat(t *testing.T) {
@@ -63,6 +66,19 @@ func TestParseFlagsAcceptsTerminalFormat(t *testing.T) {
 	}
 }
 
+func TestParseFlagsAcceptsReportFormats(t *testing.T) {
+	for _, format := range []string{"svg", "pdf", "jpg", "webp", "graphviz-json", "html", "plantuml", "d2", "graphml", "gexf", "structurizr", "json", "dsm", "dsm-csv", "dsm-html", "metrics", "metrics-json", "markdown"} {
+		var stderr bytes.Buffer
+		opts, err := parseFlags([]string{"--format", format}, &stderr)
+		if err != nil {
+			t.Fatalf("unexpected parse error for %s: %v", format, err)
+		}
+		if opts.Format != format {
+			t.Fatalf("expected %s format, got %q", format, opts.Format)
+		}
+	}
+}
+
 func TestParseFlagsInvalidMode(t *testing.T) {
 	var stderr bytes.Buffer
 	_, err := parseFlags([]string{"--mode", "bad"}, &stderr)
@@ -159,6 +175,295 @@ func TestExecutePassesBazelTargetsToApp(t *testing.T) {
 	}
 }
 
+func TestExecutePassesBaselineToApp(t *testing.T) {
+	oldRun := runApp
+	defer func() { runApp = oldRun }()
+
+	var got app.Options
+	runApp = func(_ context.Context, opts app.Options, _ io.Writer) error {
+		got = opts
+		return nil
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := execute([]string{"--format", "metrics", "--baseline", "deps-baseline.json", "--update-baseline"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if got.BaselinePath != "deps-baseline.json" || !got.UpdateBaseline {
+		t.Fatalf("unexpected baseline options: %#v", got)
+	}
+}
+
+func TestExecutePassesGraphShapingFlagsToApp(t *testing.T) {
+	oldRun := runApp
+	defer func() { runApp = oldRun }()
+
+	var got app.Options
+	runApp = func(_ context.Context, opts app.Options, _ io.Writer) error {
+		got = opts
+		return nil
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := execute([]string{"--format", "dot", "--collapse-packages", "--hide-external", "--cluster-by", "project"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if !got.CollapsePackages || !got.HideExternal || got.ClusterBy != "project" {
+		t.Fatalf("expected graph shaping flags, got %#v", got)
+	}
+}
+
+func TestParseFlagsRejectsInvalidClusterBy(t *testing.T) {
+	var stderr bytes.Buffer
+	for _, value := range []string{"module", "regex:("} {
+		_, err := parseFlags([]string{"--cluster-by", value}, &stderr)
+		if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %q, got %v", value, err)
+		}
+	}
+}
+
+func TestParseFlagsCollectsNodeFilters(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--exclude", "*Mocks", "--exclude", "regex:TestSupport$", "--exclude-kind", "external_product", "--include", "//ios/...", "--include-type", "app", "--exclude-type", "test", "--exclude-type", "ui_test", "--filter-mode", "bridge"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	got := appOptions(opts)
+	if len(got.Exclude) != 2 || got.Exclude[1] != "regex:TestSupport$" || len(got.Include) != 1 || len(got.ExcludeKinds) != 1 || len(got.IncludeTypes) != 1 || len(got.ExcludeTypes) != 2 || got.FilterMode != "bridge" {
+		t.Fatalf("unexpected filter options %#v", got)
+	}
+
+	for _, args := range [][]string{
+		{"--exclude", "regex:("},
+		{"--exclude-kind", "module"},
+		{"--include-type", "application"},
+		{"--filter-mode", "hide"},
+	} {
+		if _, err := parseFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestParseFlagsStructurizrMappingRequiresStructurizrFormat(t *testing.T) {
+	var stderr bytes.Buffer
+	_, err := parseFlags([]string{"--format", "dot", "--structurizr-mapping", "c4.json"}, &stderr)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+	opts, err := parseFlags([]string{"--format", "structurizr", "--structurizr-mapping", "c4.json"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if appOptions(opts).StructurizrMapping != "c4.json" {
+		t.Fatalf("expected mapping path to reach app options, got %#v", opts)
+	}
+}
+
+func TestParseFlagsTerminalStyleFlags(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--format", "terminal", "--unicode", "--color", "never", "--max-depth", "2", "--invert"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	got := appOptions(opts)
+	if !got.Unicode || got.Color != "never" || got.MaxDepth != 2 || !got.Invert {
+		t.Fatalf("expected terminal style flags in app options, got %#v", got)
+	}
+
+	for _, args := range [][]string{{"--color", "sometimes"}, {"--max-depth", "-1"}} {
+		if _, err := parseFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestParseFlagsMermaidStyleFlags(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--format", "mermaid", "--direction", "RL", "--link-base", "https://example.com/repo/tree/main"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	got := appOptions(opts)
+	if got.Direction != "RL" || got.LinkBase != "https://example.com/repo/tree/main" {
+		t.Fatalf("expected mermaid style flags in app options, got %#v", got)
+	}
+
+	if _, err := parseFlags([]string{"--direction", "TB"}, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+}
+
+func TestParseFlagsThemeRequiresDotOrPNG(t *testing.T) {
+	var stderr bytes.Buffer
+	if _, err := parseFlags([]string{"--format", "mermaid", "--theme", "dark"}, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+	for _, format := range []string{"dot", "png", "svg"} {
+		opts, err := parseFlags([]string{"--format", format, "--theme", "print"}, &stderr)
+		if err != nil {
+			t.Fatalf("unexpected parse error for %s: %v", format, err)
+		}
+		if appOptions(opts).Theme != "print" {
+			t.Fatalf("expected theme to reach app options, got %#v", opts)
+		}
+	}
+}
+
+func TestParseFlagsInfersFormatFromOutputExtension(t *testing.T) {
+	var stderr bytes.Buffer
+	cases := []struct {
+		args   []string
+		format string
+	}{
+		{args: []string{"--output", "docs/deps.svg"}, format: "svg"},
+		{args: []string{"--output", "deck/deps.pdf", "--layout", "sfdp"}, format: "pdf"},
+		{args: []string{"--output", "deps.json"}, format: "png"},
+		{args: []string{"--format", "graphviz-json", "--output", "deps.json"}, format: "graphviz-json"},
+		{args: []string{"--output", "deps.dot"}, format: "dot"},
+		{args: []string{"--output", "deps.txt"}, format: "png"},
+		{args: []string{"--format", "mermaid", "--output", "deps.svg"}, format: "mermaid"},
+	}
+	for _, tc := range cases {
+		opts, err := parseFlags(tc.args, &stderr)
+		if err != nil {
+			t.Fatalf("unexpected parse error for %v: %v", tc.args, err)
+		}
+		if opts.Format != tc.format {
+			t.Fatalf("expected format %q for %v, got %q", tc.format, tc.args, opts.Format)
+		}
+	}
+}
+
+func TestParseFlagsLayoutRequiresGraphvizImageFormat(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--format", "svg", "--layout", "circo"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if appOptions(opts).Layout != "circo" {
+		t.Fatalf("expected layout to reach app options, got %#v", opts)
+	}
+	for _, args := range [][]string{{"--format", "svg", "--layout", "spring"}, {"--format", "dot", "--layout", "neato"}} {
+		if _, err := parseFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestParseFlagsCollectsRepeatedEmit(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--emit", "mermaid=docs/deps.md,png=docs/deps.png", "--emit", "json=build/deps.json", "--theme", "light"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	got := appOptions(opts).Emit
+	want := []app.Artifact{
+		{Format: "mermaid", OutputPath: "docs/deps.md"},
+		{Format: "png", OutputPath: "docs/deps.png"},
+		{Format: "json", OutputPath: "build/deps.json"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d artifacts, got %#v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("artifact %d: expected %#v, got %#v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestParseFlagsRejectsInvalidEmit(t *testing.T) {
+	for _, args := range [][]string{
+		{"--emit", "bmp=deps.bmp"},
+		{"--emit", "mermaid=deps.md", "--format", "dot"},
+		{"--emit", "mermaid=deps.md", "--output", "deps.dot"},
+		{"--emit", "mermaid=deps.md", "--theme", "dark"},
+	} {
+		var stderr bytes.Buffer
+		if _, err := parseFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestParseFlagsInjectDefaultsToMermaid(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--inject", "README.md", "--check"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	got := appOptions(opts)
+	if got.Format != "mermaid" || got.InjectPath != "README.md" || !got.Check {
+		t.Fatalf("unexpected inject options: %#v", got)
+	}
+
+	opts, err = parseFlags([]string{"--inject", "README.md", "--format", "terminal"}, &stderr)
+	if err != nil || opts.Format != "terminal" {
+		t.Fatalf("expected explicit terminal format, got %q, %v", opts.Format, err)
+	}
+}
+
+func TestParseFlagsRejectsInvalidInject(t *testing.T) {
+	for _, args := range [][]string{
+		{"--check"},
+		{"--inject", "README.md", "--format", "png"},
+		{"--inject", "README.md", "--output", "deps.mmd"},
+		{"--inject", "README.md", "--emit", "dot=deps.dot"},
+	} {
+		var stderr bytes.Buffer
+		if _, err := parseFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestExecuteReturnsExitCodeThreeForStaleInject(t *testing.T) {
+	oldRun := runApp
+	runApp = func(context.Context, app.Options, io.Writer) error {
+		return apperrors.New(apperrors.KindOutputStale, "stale", nil)
+	}
+	defer func() { runApp = oldRun }()
+
+	var stdout, stderr bytes.Buffer
+	if code := execute([]string{"--inject", "README.md", "--check"}, &stdout, &stderr); code != 3 {
+		t.Fatalf("expected exit code 3, got %d", code)
+	}
+}
+
+func TestParseFlagsWatch(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, err := parseFlags([]string{"--watch", "--format", "svg", "--output", "deps.svg"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if !appOptions(opts).Watch {
+		t.Fatalf("expected watch to be forwarded")
+	}
+
+	for _, args := range [][]string{
+		{"--watch", "--inject", "README.md", "--check"},
+		{"--watch", "--baseline", "base.json", "--update-baseline"},
+	} {
+		if _, err := parseFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestParseFlagsUpdateBaselineRequiresBaseline(t *testing.T) {
+	var stderr bytes.Buffer
+	_, err := parseFlags([]string{"--update-baseline"}, &stderr)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+}
+
 func TestExecuteWarnsWhenSPMModeIgnoresXcodeFlags(t *testing.T) {
 	oldRun := runApp
 	runApp = func(_ context.Context, _ app.Options, _ io.Writer) error {
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"swift-deps-diagram/internal/app"
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+// runPath allows tests to inject a fake path query runner.
+var runPath = app.RunPath
+
+func parsePathFlags(args []string, stderr io.Writer) (cliOptions, app.PathQuery, error) {
+	fs := flag.NewFlagSet("swift-deps-diagram path", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	opts := cliOptions{}
+	query := app.PathQuery{}
+	registerGraphFlags(fs, &opts)
+	fs.StringVar(&opts.Format, "format", "terminal", "Output format: "+app.PathFormatUsage())
+	fs.StringVar(&query.From, "from", "", "Dependent node label or ID where paths start")
+	fs.StringVar(&query.To, "to", "", "Dependency node label or ID where paths end")
+	fs.BoolVar(&query.All, "all", false, "Print every simple path instead of the shortest one")
+
+	if err := fs.Parse(args); err != nil {
+		return cliOptions{}, app.PathQuery{}, apperrors.New(apperrors.KindInvalidArgs, "invalid arguments", err)
+	}
+	configPath, _, err := applyConfig(fs, opts.Path, opts.View, inputConfigKeys)
+	if err != nil {
+		return cliOptions{}, app.PathQuery{}, err
+	}
+	opts.ConfigPath = configPath
+
+	switch opts.Format {
+	case "terminal", "dot", "mermaid", "json":
+	default:
+		return cliOptions{}, app.PathQuery{}, apperrors.New(apperrors.KindInvalidArgs, "--format must be one of: "+app.PathFormatUsage(), nil)
+	}
+	if err := validateInputFlags(opts); err != nil {
+		return cliOptions{}, app.PathQuery{}, err
+	}
+	if query.From == "" || query.To == "" {
+		return cliOptions{}, app.PathQuery{}, apperrors.New(apperrors.KindInvalidArgs, "path requires --from and --to", nil)
+	}
+
+	if fs.NArg() > 0 {
+		return cliOptions{}, app.PathQuery{}, apperrors.New(apperrors.KindInvalidArgs, "unexpected positional arguments", nil)
+	}
+
+	return opts, query, nil
+}
+
+func executePath(args []string, stdout, stderr io.Writer) int {
+	opts, query, err := parsePathFlags(args, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return apperrors.ExitCode(err)
+	}
+	reportConfig(opts, stderr)
+	warnIgnoredFlags(opts, stderr)
+
+	if err := runPath(context.Background(), appOptions(opts), query, stdout); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return apperrors.ExitCode(err)
+	}
+	return 0
+}
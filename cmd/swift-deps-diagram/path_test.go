@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"swift-deps-diagram/internal/app"
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+func TestParsePathFlagsDefaults(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, query, err := parsePathFlags([]string{"--from", "Widget", "--to", "Networking"}, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if opts.Format != "terminal" || opts.Mode != "auto" || opts.Path != "." {
+		t.Fatalf("unexpected defaults: %#v", opts)
+	}
+	if query.From != "Widget" || query.To != "Networking" || query.All {
+		t.Fatalf("unexpected query: %#v", query)
+	}
+}
+
+func TestParsePathFlagsRequiresEndpoints(t *testing.T) {
+	var stderr bytes.Buffer
+	_, _, err := parsePathFlags([]string{"--from", "Widget"}, &stderr)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+}
+
+func TestParsePathFlagsRejectsImageFormats(t *testing.T) {
+	var stderr bytes.Buffer
+	_, _, err := parsePathFlags([]string{"--from", "A", "--to", "B", "--format", "png"}, &stderr)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+}
+
+func TestExecuteDispatchesPathSubcommand(t *testing.T) {
+	oldRunPath := runPath
+	defer func() { runPath = oldRunPath }()
+
+	var gotOpts app.Options
+	var gotQuery app.PathQuery
+	runPath = func(_ context.Context, opts app.Options, query app.PathQuery, _ io.Writer) error {
+		gotOpts = opts
+		gotQuery = query
+		return nil
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	code := execute([]string{"path", "--from", "Widget", "--to", "Networking", "--all", "--format", "json", "--mode", "bazel"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if gotOpts.Format != "json" || gotOpts.Mode != "bazel" {
+		t.Fatalf("unexpected options: %#v", gotOpts)
+	}
+	if gotQuery != (app.PathQuery{From: "Widget", To: "Networking", All: true}) {
+		t.Fatalf("unexpected query: %#v", gotQuery)
+	}
+}
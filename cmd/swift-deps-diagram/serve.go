@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"swift-deps-diagram/internal/app"
+	"swift-deps-diagram/internal/cluster"
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graphviz"
+	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/theme"
+)
+
+// runServe allows tests to inject a fake server runner.
+var runServe = app.RunServe
+
+func parseServeFlags(args []string, stderr io.Writer) (cliOptions, app.ServeOptions, error) {
+	fs := flag.NewFlagSet("swift-deps-diagram serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	opts := cliOptions{}
+	serve := app.ServeOptions{}
+	registerGraphFlags(fs, &opts)
+	fs.StringVar(&serve.Addr, "addr", app.DefaultServeAddr, "Address the HTTP server listens on")
+	fs.StringVar(&opts.ClusterBy, "cluster-by", "", "Group nodes into clusters for dot/Graphviz images/mermaid/plantuml/d2: "+cluster.Usage)
+	fs.StringVar(&opts.Theme, "theme", "", "DOT and Graphviz image theme: "+theme.PresetUsage+" or a theme JSON file")
+	fs.StringVar(&opts.Layout, "layout", "", "Graphviz layout engine for image endpoints: "+app.LayoutUsage()+" (default dot)")
+	fs.StringVar(&opts.Direction, "direction", "TD", "Mermaid flowchart direction: "+strings.Join(render.MermaidDirections, "|"))
+	fs.StringVar(&opts.LinkBase, "link-base", "", "Repository URL or directory prefix for Mermaid click links to each node's source path")
+
+	if err := fs.Parse(args); err != nil {
+		return cliOptions{}, app.ServeOptions{}, apperrors.New(apperrors.KindInvalidArgs, "invalid arguments", err)
+	}
+	configPath, _, err := applyConfig(fs, opts.Path, opts.View, serveConfigKeys)
+	if err != nil {
+		return cliOptions{}, app.ServeOptions{}, err
+	}
+	opts.ConfigPath = configPath
+
+	if err := validateInputFlags(opts); err != nil {
+		return cliOptions{}, app.ServeOptions{}, err
+	}
+	if opts.Output != "" {
+		return cliOptions{}, app.ServeOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--output is not supported by serve", nil)
+	}
+	if serve.Addr == "" {
+		return cliOptions{}, app.ServeOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--addr cannot be empty", nil)
+	}
+	if _, err := cluster.Parse(opts.ClusterBy); err != nil {
+		return cliOptions{}, app.ServeOptions{}, err
+	}
+	if opts.Layout != "" && !graphviz.IsEngine(opts.Layout) {
+		return cliOptions{}, app.ServeOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--layout must be one of: "+app.LayoutUsage(), nil)
+	}
+	if !render.IsValidMermaidDirection(opts.Direction) {
+		return cliOptions{}, app.ServeOptions{}, apperrors.New(apperrors.KindInvalidArgs, "--direction must be one of: "+strings.Join(render.MermaidDirections, "|"), nil)
+	}
+
+	if fs.NArg() > 0 {
+		return cliOptions{}, app.ServeOptions{}, apperrors.New(apperrors.KindInvalidArgs, "unexpected positional arguments", nil)
+	}
+
+	return opts, serve, nil
+}
+
+func executeServe(args []string, stdout, stderr io.Writer) int {
+	opts, serve, err := parseServeFlags(args, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return apperrors.ExitCode(err)
+	}
+	reportConfig(opts, stderr)
+	warnIgnoredFlags(opts, stderr)
+
+	// Ctrl-C stops the server normally.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := runServe(ctx, appOptions(opts), serve, stdout); err != nil {
+		fmt.Fprintln(stderr, err.Error())
+		return apperrors.ExitCode(err)
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"swift-deps-diagram/internal/app"
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+func TestParseServeFlagsDefaults(t *testing.T) {
+	var stderr bytes.Buffer
+	opts, serve, err := parseServeFlags(nil, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if serve.Addr != app.DefaultServeAddr || opts.Mode != "auto" || opts.Path != "." || opts.Direction != "TD" {
+		t.Fatalf("unexpected defaults: %#v %#v", opts, serve)
+	}
+}
+
+func TestParseServeFlagsRejectsInvalidOptions(t *testing.T) {
+	for _, args := range [][]string{
+		{"--addr", ""},
+		{"--output", "deps.svg"},
+		{"--layout", "twopi"},
+		{"--direction", "UP"},
+		{"--cluster-by", "bogus"},
+		{"extra"},
+	} {
+		var stderr bytes.Buffer
+		if _, _, err := parseServeFlags(args, &stderr); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestExecuteDispatchesServeSubcommand(t *testing.T) {
+	oldRunServe := runServe
+	defer func() { runServe = oldRunServe }()
+
+	var gotOpts app.Options
+	var gotServe app.ServeOptions
+	runServe = func(_ context.Context, opts app.Options, serve app.ServeOptions, _ io.Writer) error {
+		gotOpts = opts
+		gotServe = serve
+		return nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := execute([]string{"serve", "--addr", ":9000", "--mode", "spm", "--hide-external", "--direction", "LR"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if gotServe.Addr != ":9000" || gotOpts.Mode != "spm" || !gotOpts.HideExternal || gotOpts.Direction != "LR" {
+		t.Fatalf("unexpected serve call: %#v %#v", gotOpts, gotServe)
+	}
+}
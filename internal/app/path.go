@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/pathquery"
+	"swift-deps-diagram/internal/render"
+)
+
+var findPaths = pathquery.Find
+
+// PathQuery selects the endpoints of a path subcommand run.
+type PathQuery struct {
+	From string
+	To   string
+	All  bool
+}
+
+var pathFormats = []string{"terminal", "dot", "mermaid", "json"}
+
+// PathFormatUsage lists the supported path --format values for help and error text.
+func PathFormatUsage() string {
+	return strings.Join(pathFormats, "|")
+}
+
+func validatePathQuery(opts Options, query PathQuery) error {
+	if err := validateInputOptions(opts); err != nil {
+		return err
+	}
+	if query.From == "" || query.To == "" {
+		return apperrors.New(apperrors.KindInvalidArgs, "path requires --from and --to", nil)
+	}
+	for _, format := range pathFormats {
+		if opts.Format == format {
+			return nil
+		}
+	}
+	return apperrors.New(apperrors.KindInvalidArgs, "--format must be one of: "+PathFormatUsage(), nil)
+}
+
+func renderPathResult(g graph.Graph, result pathquery.Result, format string) (string, error) {
+	switch format {
+	case "terminal":
+		return pathquery.Text(g, result), nil
+	case "json":
+		return pathquery.JSON(g, result)
+	case "dot":
+		return renderDot(result.Subgraph(g), render.DotOptions{Highlight: result.NodeIDs()})
+	case "mermaid":
+		return renderMermaid(result.Subgraph(g), render.MermaidOptions{Highlight: result.NodeIDs()})
+	default:
+		return "", apperrors.New(apperrors.KindInvalidArgs, "unsupported format", nil)
+	}
+}
+
+// RunPath loads the graph and writes the dependency chains connecting query.From and query.To.
+// A query without paths writes nothing and fails with KindNoPath in every format.
+func RunPath(ctx context.Context, opts Options, query PathQuery, stdout io.Writer) error {
+	if err := validatePathQuery(opts, query); err != nil {
+		return err
+	}
+	g, err := loadGraph(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	result, err := findPaths(g, query.From, query.To, query.All)
+	if err != nil {
+		return err
+	}
+	if len(result.Paths) == 0 {
+		return apperrors.New(apperrors.KindNoPath, pathquery.NoPathMessage(g, result), nil)
+	}
+	rendered, err := renderPathResult(g, result, opts.Format)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(rendered, opts.OutputPath, stdout); err != nil {
+		return err
+	}
+	if opts.Verbose && opts.OutputPath != "" {
+		logInfof("generated %s path content at %s", opts.Format, opts.OutputPath)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/manifest"
+	"swift-deps-diagram/internal/render"
+)
+
+func pathGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::Widget":     {ID: "target::Widget", Label: "Widget", Kind: graph.NodeKindTarget},
+			"target::Core":       {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"target::Other":      {ID: "target::Other", Label: "Other", Kind: graph.NodeKindTarget},
+			"pkg::x::Networking": {ID: "pkg::x::Networking", Label: "Networking", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::Widget", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::x::Networking", Kind: graph.EdgeKindProduct},
+			{FromID: "target::Other", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+		},
+	}
+}
+
+func TestRunPathWritesTerminalList(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) { return pathGraph(), nil }
+
+	err := RunPath(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "terminal"}, PathQuery{From: "Widget", To: "Networking"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.textOutput != "Widget -> Core -> Networking" {
+		t.Fatalf("unexpected path output %q", h.textOutput)
+	}
+}
+
+func TestRunPathRendersHighlightedSubgraph(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) { return pathGraph(), nil }
+	renderDot = render.DotWithOptions
+
+	err := RunPath(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot"}, PathQuery{From: "Widget", To: "Networking"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if strings.Contains(h.textOutput, "Other") {
+		t.Fatalf("expected only path nodes, got %q", h.textOutput)
+	}
+	highlighted := 0
+	for _, line := range strings.Split(h.textOutput, "\n") {
+		if strings.Contains(line, "[label=") && !strings.Contains(line, "->") {
+			if !strings.Contains(line, "penwidth=2") {
+				t.Fatalf("expected every path node highlighted, got %q", line)
+			}
+			highlighted++
+		}
+	}
+	if highlighted != 3 {
+		t.Fatalf("expected 3 highlighted path nodes, got %q", h.textOutput)
+	}
+}
+
+func TestRunPathFailsWithoutPathInEveryFormat(t *testing.T) {
+	dir := withManifestDir(t)
+	for _, format := range []string{"terminal", "json", "dot", "mermaid"} {
+		h := stubAppDeps(t)
+		buildGraph = func(manifest.Package, bool) (graph.Graph, error) { return pathGraph(), nil }
+
+		err := RunPath(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: format}, PathQuery{From: "Networking", To: "Widget"}, &bytes.Buffer{})
+		if !apperrors.IsKind(err, apperrors.KindNoPath) || err.Error() != "no dependency path from Networking to Widget" {
+			t.Fatalf("%s: expected no path error, got %v", format, err)
+		}
+		if h.textOutput != "" {
+			t.Fatalf("%s: expected no output, got %q", format, h.textOutput)
+		}
+	}
+}
+
+func TestRunPathValidatesQuery(t *testing.T) {
+	err := RunPath(context.Background(), Options{PackagePath: ".", Mode: "auto", Format: "terminal"}, PathQuery{From: "A"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for missing --to, got %v", err)
+	}
+	err = RunPath(context.Background(), Options{PackagePath: ".", Mode: "auto", Format: "png"}, PathQuery{From: "A", To: "B"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for png format, got %v", err)
+	}
+}
@@ -6,17 +6,26 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"swift-deps-diagram/internal/baseline"
 	"swift-deps-diagram/internal/bazel"
 	"swift-deps-diagram/internal/bazelgraph"
+	"swift-deps-diagram/internal/cluster"
+	"swift-deps-diagram/internal/dsm"
 	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/filter"
 	"swift-deps-diagram/internal/graph"
 	"swift-deps-diagram/internal/graphviz"
 	"swift-deps-diagram/internal/inputresolve"
 	"swift-deps-diagram/internal/manifest"
+	"swift-deps-diagram/internal/metrics"
 	"swift-deps-diagram/internal/output"
+	"swift-deps-diagram/internal/pins"
 	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/structurizr"
 	"swift-deps-diagram/internal/swiftpm"
+	"swift-deps-diagram/internal/theme"
 	"swift-deps-diagram/internal/tuist"
 	"swift-deps-diagram/internal/xcodegraph"
 	"swift-deps-diagram/internal/xcodeproj"
@@ -31,11 +40,43 @@ var generateTuistProject = tuist.Generate
 var buildXcodeGraph = xcodegraph.Build
 var loadBazelWorkspace = bazel.LoadWorkspace
 var buildBazelGraph = bazelgraph.Build
-var renderMermaid = render.Mermaid
-var renderDot = render.Dot
-var renderTerminal = render.Terminal
+var renderMermaid = render.MermaidWithOptions
+var renderDot = render.DotWithOptions
+var loadTheme = theme.Resolve
+var renderTerminal = render.TerminalWithOptions
+var renderHTML = render.HTML
+var renderPlantUML = render.PlantUMLWithOptions
+var renderD2 = render.D2WithOptions
+var renderJSON = render.JSON
+var renderGraphML = render.GraphML
+var renderGEXF = render.GEXF
+var loadStructurizrMapping = structurizr.LoadMapping
+var renderStructurizr = structurizr.DSL
+var findPackageVersions = pins.Find
+var renderMarkdown = render.Markdown
+var computeMetrics = metrics.Compute
+var renderMetricsText = metrics.Text
+var renderMetricsJSON = metrics.JSON
+var buildDSM = dsm.Build
+var renderDSMText = dsm.Text
+var renderDSMCSV = dsm.CSV
+var renderDSMHTML = dsm.HTML
 var writeOutput = output.Write
-var writePNG = graphviz.WritePNG
+var injectOutput = output.Inject
+var exportGraphviz = graphviz.Export
+var snapshotGraph = baseline.FromGraph
+var loadBaseline = baseline.Load
+var saveBaseline = baseline.Save
+var compareBaseline = baseline.Compare
+var lookupEnv = os.LookupEnv
+var isTerminal = func(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 var logInfof = func(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, format+"\n", args...)
 }
@@ -51,9 +92,304 @@ type Options struct {
 	OutputPath    string
 	Verbose       bool
 	IncludeTests  bool
+	// BaselinePath points at a stored graph snapshot to compare against.
+	BaselinePath string
+	// UpdateBaseline rewrites BaselinePath from the current graph instead of comparing.
+	UpdateBaseline bool
+	// CollapsePackages merges the external products of each package into one node.
+	CollapsePackages bool
+	// HideExternal drops external product nodes.
+	HideExternal bool
+	// Include keeps only nodes whose label or ID matches one of these patterns (see
+	// filter.PatternUsage); empty keeps every node.
+	Include []string
+	// Exclude removes nodes whose label or ID matches one of these patterns.
+	Exclude []string
+	// ExcludeKinds removes nodes of these kinds.
+	ExcludeKinds []string
+	// IncludeTypes keeps only targets of these types; ExcludeTypes removes targets of
+	// these types. Neither affects external products.
+	IncludeTypes []string
+	ExcludeTypes []string
+	// FilterMode is drop or bridge; bridge connects the neighbors of removed nodes.
+	FilterMode string
+	// StructurizrMapping points at a JSON file assigning targets to C4 containers.
+	StructurizrMapping string
+	// ClusterBy groups nodes into DOT clusters and Mermaid subgraphs (see cluster.Usage).
+	ClusterBy string
+	// Unicode draws terminal trees with box-drawing characters.
+	Unicode bool
+	// Color controls ANSI colors in terminal trees: auto, always, or never.
+	Color string
+	// MaxDepth limits how deep terminal trees are expanded; zero means no limit.
+	MaxDepth int
+	// Invert renders terminal trees bottom-up, listing the dependents of each leaf.
+	Invert bool
+	// Direction is the Mermaid flowchart direction (TD, LR, BT, RL); empty means TD.
+	Direction string
+	// LinkBase adds Mermaid click links to LinkBase/<node path> when set.
+	LinkBase string
+	// Theme is a built-in DOT theme preset or the path of a theme JSON file.
+	Theme string
+	// Layout is the Graphviz layout engine for image formats; empty means dot.
+	Layout string
+	// Emit lists several artifacts rendered from one graph; when set it replaces
+	// Format and OutputPath.
+	Emit []Artifact
+	// InjectPath is a Markdown file whose marked region is replaced with the rendered
+	// output instead of writing OutputPath.
+	InjectPath string
+	// Check reports a stale InjectPath as an error instead of rewriting it.
+	Check bool
+	// Watch keeps running and regenerates the outputs whenever an input manifest changes.
+	Watch bool
+}
+
+// Artifact is one rendered output of a run. An empty OutputPath means stdout for text
+// formats and deps.<ext> for Graphviz formats.
+type Artifact struct {
+	Format     string
+	OutputPath string
+}
+
+// EmitUsage describes the --emit syntax for help and error text.
+const EmitUsage = "format=path[,format=path...]"
+
+// ParseEmit parses a comma-separated list of format=path pairs. A bare format writes
+// to stdout (or deps.<ext> for Graphviz formats).
+func ParseEmit(spec string) ([]Artifact, error) {
+	var out []Artifact
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		format, outputPath, _ := strings.Cut(entry, "=")
+		format = strings.TrimSpace(format)
+		if !IsValidFormat(format) {
+			return nil, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("--emit entry %q: format must be one of: %s", entry, FormatUsage()), nil)
+		}
+		out = append(out, Artifact{Format: format, OutputPath: strings.TrimSpace(outputPath)})
+	}
+	if len(out) == 0 {
+		return nil, apperrors.New(apperrors.KindInvalidArgs, "--emit must list at least one "+EmitUsage, nil)
+	}
+	return out, nil
+}
+
+// artifacts returns the outputs requested by opts: Emit when set, otherwise the single
+// Format/OutputPath pair.
+func artifacts(opts Options) []Artifact {
+	if len(opts.Emit) > 0 {
+		return opts.Emit
+	}
+	return []Artifact{{Format: opts.Format, OutputPath: opts.OutputPath}}
+}
+
+// withArtifact returns opts narrowed to render a single artifact.
+func withArtifact(opts Options, artifact Artifact) Options {
+	opts.Format = artifact.Format
+	opts.OutputPath = artifact.OutputPath
+	opts.Emit = nil
+	return opts
+}
+
+// injectFences maps the formats accepted by --inject to the language of the fenced code
+// block they are wrapped in. Markdown reports are injected as-is.
+var injectFences = map[string]string{
+	"mermaid":  "mermaid",
+	"terminal": "text",
+	"dot":      "dot",
+	"plantuml": "plantuml",
+	"d2":       "d2",
+	"markdown": "",
+}
+
+// InjectFormatUsage lists the formats accepted by --inject for help and error text.
+const InjectFormatUsage = "mermaid|terminal|dot|plantuml|d2|markdown"
+
+// IsInjectFormat reports whether format can be injected into a Markdown file.
+func IsInjectFormat(format string) bool {
+	_, ok := injectFences[format]
+	return ok
+}
+
+// injectBlock wraps rendered output in the fenced code block used for format.
+func injectBlock(format, rendered string) string {
+	rendered = strings.TrimRight(rendered, "\n")
+	if format == "markdown" {
+		return rendered
+	}
+	return "```" + injectFences[format] + "\n" + rendered + "\n```"
+}
+
+// ColorUsage lists the supported --color values for help and error text.
+const ColorUsage = "auto|always|never"
+
+func isValidColor(color string) bool {
+	switch color {
+	case "", "auto", "always", "never":
+		return true
+	default:
+		return false
+	}
+}
+
+var supportedFormats = []string{"mermaid", "dot", "png", "svg", "pdf", "jpg", "webp", "graphviz-json", "terminal", "json", "html", "plantuml", "d2", "graphml", "gexf", "structurizr", "dsm", "dsm-csv", "dsm-html", "metrics", "metrics-json", "markdown"}
+
+// graphvizFormats maps the --format values rendered by Graphviz to their -T format.
+var graphvizFormats = map[string]string{
+	"png":           "png",
+	"svg":           "svg",
+	"pdf":           "pdf",
+	"jpg":           "jpg",
+	"webp":          "webp",
+	"graphviz-json": "json",
+}
+
+// IsGraphvizFormat reports whether format is rendered into a file by Graphviz.
+func IsGraphvizFormat(format string) bool {
+	_, ok := graphvizFormats[format]
+	return ok
+}
+
+// FormatForOutput infers the --format value from the extension of outputPath: DOT
+// source for .dot/.gv and the matching Graphviz format for image and PDF files.
+// A .json path is ambiguous between json, metrics-json, and graphviz-json, so it
+// is never inferred.
+func FormatForOutput(outputPath string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".dot", ".gv":
+		return "dot", true
+	case ".json":
+		return "", false
+	}
+	gvFormat, ok := graphviz.FormatForPath(outputPath)
+	if !ok {
+		return "", false
+	}
+	for format, candidate := range graphvizFormats {
+		if candidate == gvFormat {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// LayoutUsage lists the supported --layout values for help and error text.
+func LayoutUsage() string {
+	return strings.Join(graphviz.Engines, "|")
+}
+
+// IsValidFormat reports whether format is a supported --format value.
+func IsValidFormat(format string) bool {
+	for _, supported := range supportedFormats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatUsage lists the supported --format values for help and error text.
+func FormatUsage() string {
+	return strings.Join(supportedFormats, "|")
+}
+
+// anyArtifact reports whether match holds for the format of at least one artifact.
+func anyArtifact(opts Options, match func(format string) bool) bool {
+	for _, artifact := range artifacts(opts) {
+		if match(artifact.Format) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateArtifacts(opts Options) error {
+	seenPaths := make(map[string]struct{})
+	stdoutCount := 0
+	for _, artifact := range artifacts(opts) {
+		if !IsValidFormat(artifact.Format) {
+			return apperrors.New(apperrors.KindInvalidArgs, "--format must be one of: "+FormatUsage(), nil)
+		}
+		outputPath := artifact.OutputPath
+		if outputPath == "" {
+			if gvFormat, ok := graphvizFormats[artifact.Format]; ok {
+				outputPath = "deps." + gvFormat
+			} else {
+				stdoutCount++
+				continue
+			}
+		}
+		key := filepath.Clean(outputPath)
+		if _, ok := seenPaths[key]; ok {
+			return apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("--emit writes %s more than once", outputPath), nil)
+		}
+		seenPaths[key] = struct{}{}
+	}
+	if stdoutCount > 1 {
+		return apperrors.New(apperrors.KindInvalidArgs, "--emit can write at most one artifact to stdout", nil)
+	}
+	return nil
 }
 
 func validateOptions(opts Options) error {
+	if err := validateInputOptions(opts); err != nil {
+		return err
+	}
+	if err := validateArtifacts(opts); err != nil {
+		return err
+	}
+	if opts.UpdateBaseline && opts.BaselinePath == "" {
+		return apperrors.New(apperrors.KindInvalidArgs, "--update-baseline requires --baseline", nil)
+	}
+	if opts.Watch && (opts.Check || opts.UpdateBaseline) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--watch cannot be combined with --check or --update-baseline", nil)
+	}
+	if opts.Check && opts.InjectPath == "" {
+		return apperrors.New(apperrors.KindInvalidArgs, "--check requires --inject", nil)
+	}
+	if opts.InjectPath != "" {
+		if len(opts.Emit) > 0 || opts.OutputPath != "" {
+			return apperrors.New(apperrors.KindInvalidArgs, "--inject cannot be combined with --output or --emit", nil)
+		}
+		if !IsInjectFormat(opts.Format) {
+			return apperrors.New(apperrors.KindInvalidArgs, "--inject requires --format "+InjectFormatUsage, nil)
+		}
+	}
+	isStructurizr := func(format string) bool { return format == "structurizr" }
+	if opts.StructurizrMapping != "" && !anyArtifact(opts, isStructurizr) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--structurizr-mapping requires --format structurizr", nil)
+	}
+	if _, err := cluster.Parse(opts.ClusterBy); err != nil {
+		return err
+	}
+	if !isValidColor(opts.Color) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--color must be one of: "+ColorUsage, nil)
+	}
+	isDot := func(format string) bool { return format == "dot" || IsGraphvizFormat(format) }
+	if opts.Theme != "" && !anyArtifact(opts, isDot) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--theme requires --format dot or a Graphviz image format", nil)
+	}
+	if opts.Layout != "" {
+		if !graphviz.IsEngine(opts.Layout) {
+			return apperrors.New(apperrors.KindInvalidArgs, "--layout must be one of: "+LayoutUsage(), nil)
+		}
+		if !anyArtifact(opts, IsGraphvizFormat) {
+			return apperrors.New(apperrors.KindInvalidArgs, "--layout requires a Graphviz image format", nil)
+		}
+	}
+	if opts.Direction != "" && !render.IsValidMermaidDirection(opts.Direction) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--direction must be one of: "+strings.Join(render.MermaidDirections, "|"), nil)
+	}
+	if opts.MaxDepth < 0 {
+		return apperrors.New(apperrors.KindInvalidArgs, "--max-depth cannot be negative", nil)
+	}
+	return nil
+}
+
+func validateInputOptions(opts Options) error {
 	if opts.PackagePath == "" {
 		return apperrors.New(apperrors.KindInvalidArgs, "--path cannot be empty", nil)
 	}
@@ -66,27 +402,126 @@ func validateOptions(opts Options) error {
 	if opts.ProjectPath != "" && opts.WorkspacePath != "" {
 		return apperrors.New(apperrors.KindInvalidArgs, "--project and --workspace cannot be used together", nil)
 	}
-	switch opts.Format {
-	case "mermaid", "dot", "png", "terminal":
-	default:
-		return apperrors.New(apperrors.KindInvalidArgs, "--format must be one of: mermaid|dot|png|terminal", nil)
+	if _, err := filter.Parse(filterSpec(opts)); err != nil {
+		return err
 	}
 	return nil
 }
 
-func renderTextOutput(g graph.Graph, format string) (string, error) {
-	switch format {
+func filterSpec(opts Options) filter.Spec {
+	return filter.Spec{Include: opts.Include, Exclude: opts.Exclude, ExcludeKinds: opts.ExcludeKinds, IncludeTypes: opts.IncludeTypes, ExcludeTypes: opts.ExcludeTypes, Mode: opts.FilterMode}
+}
+
+// nodeGroups assigns nodes to clusters according to opts.ClusterBy.
+func nodeGroups(g graph.Graph, opts Options) (map[string]string, error) {
+	rule, err := cluster.Parse(opts.ClusterBy)
+	if err != nil {
+		return nil, err
+	}
+	return rule.Assign(g), nil
+}
+
+func renderTextOutput(g graph.Graph, opts Options, resolved inputresolve.Resolved) (string, error) {
+	groups, err := nodeGroups(g, opts)
+	if err != nil {
+		return "", err
+	}
+
+	switch opts.Format {
 	case "mermaid":
-		return renderMermaid(g)
+		return renderMermaid(g, render.MermaidOptions{Groups: groups, Direction: opts.Direction, LinkBase: opts.LinkBase})
 	case "dot":
-		return renderDot(g)
+		return renderDotOutput(g, opts, groups)
+	case "plantuml":
+		return renderPlantUML(g, render.PlantUMLOptions{Groups: groups})
+	case "d2":
+		return renderD2(g, render.D2Options{Groups: groups})
 	case "terminal":
-		return renderTerminal(g)
+		return renderTerminal(g, render.TerminalOptions{Unicode: opts.Unicode, Color: opts.Color == "always", MaxDepth: opts.MaxDepth, Invert: opts.Invert})
+	case "json":
+		return renderJSON(g)
+	case "html":
+		return renderHTML(g)
+	case "graphml":
+		return renderGraphML(g)
+	case "gexf":
+		return renderGEXF(g)
+	case "structurizr":
+		return renderStructurizrOutput(g, opts, resolved)
+	case "dsm":
+		return renderDSMText(buildDSM(g))
+	case "dsm-csv":
+		return renderDSMCSV(buildDSM(g))
+	case "dsm-html":
+		return renderDSMHTML(buildDSM(g))
+	case "metrics":
+		return renderMetricsText(computeMetrics(g))
+	case "metrics-json":
+		return renderMetricsJSON(computeMetrics(g))
+	case "markdown":
+		return renderMarkdownOutput(g, opts, resolved, groups)
 	default:
 		return "", apperrors.New(apperrors.KindInvalidArgs, "unsupported format", nil)
 	}
 }
 
+// renderDotOutput renders DOT source styled with the theme selected by opts.Theme.
+func renderDotOutput(g graph.Graph, opts Options, groups map[string]string) (string, error) {
+	t, err := loadTheme(opts.Theme)
+	if err != nil {
+		return "", err
+	}
+	return renderDot(g, render.DotOptions{Groups: groups, Theme: &t})
+}
+
+// renderStructurizrOutput renders a Structurizr workspace named after the resolved
+// input directory unless the optional mapping file names the system.
+func renderStructurizrOutput(g graph.Graph, opts Options, resolved inputresolve.Resolved) (string, error) {
+	mapping := structurizr.Mapping{}
+	if opts.StructurizrMapping != "" {
+		loaded, err := loadStructurizrMapping(opts.StructurizrMapping)
+		if err != nil {
+			return "", err
+		}
+		mapping = loaded
+	}
+	return renderStructurizr(g, mapping, filepath.Base(inputRoot(resolved)))
+}
+
+// renderMarkdownOutput renders a Markdown report named after the resolved input
+// directory, with package versions taken from the input's Package.resolved files when
+// present.
+func renderMarkdownOutput(g graph.Graph, opts Options, resolved inputresolve.Resolved, groups map[string]string) (string, error) {
+	root := inputRoot(resolved)
+	versions, err := findPackageVersions(root, resolved.ProjectPath, resolved.WorkspacePath)
+	if err != nil {
+		return "", err
+	}
+	return renderMarkdown(g, render.MarkdownOptions{
+		Title:    filepath.Base(root),
+		Versions: versions,
+		Mermaid:  render.MermaidOptions{Groups: groups, Direction: opts.Direction, LinkBase: opts.LinkBase},
+	})
+}
+
+// inputRoot returns the directory of a resolved input: the package directory, the Tuist
+// directory, the directory holding the Xcode workspace or project, or the Bazel
+// workspace.
+func inputRoot(resolved inputresolve.Resolved) string {
+	switch {
+	case resolved.PackagePath != "":
+		return absolutePath(resolved.PackagePath)
+	case resolved.TuistPath != "":
+		return absolutePath(resolved.TuistPath)
+	case resolved.WorkspacePath != "":
+		return filepath.Dir(absolutePath(resolved.WorkspacePath))
+	case resolved.ProjectPath != "":
+		return filepath.Dir(absolutePath(resolved.ProjectPath))
+	default:
+		return absolutePath(resolved.BazelWorkspacePath)
+	}
+}
+
 func absolutePath(path string) string {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -100,103 +535,265 @@ func Run(ctx context.Context, opts Options, stdout io.Writer) error {
 	if err := validateOptions(opts); err != nil {
 		return err
 	}
-	resolved, err := resolveInput(inputresolve.Request{
+	resolved, err := resolveOptions(opts)
+	if err != nil {
+		return err
+	}
+	if opts.Watch {
+		return runWatch(ctx, opts, resolved, stdout)
+	}
+	return generate(ctx, opts, resolved, stdout, nil)
+}
+
+// generate builds the graph of a resolved input, writes every requested artifact, and
+// checks the baseline. last is passed through to emit.
+func generate(ctx context.Context, opts Options, resolved inputresolve.Resolved, stdout io.Writer, last map[string]string) error {
+	built, err := buildSourceGraph(ctx, opts, resolved)
+	if err != nil {
+		return err
+	}
+	g, err := transformGraph(built, opts)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range artifacts(opts) {
+		if err := emit(ctx, g, withArtifact(opts, artifact), resolved, stdout, last); err != nil {
+			return err
+		}
+	}
+	// The baseline measures the graph as built, so display transforms such as
+	// --hide-external, --collapse-packages, and node filters cannot hide growth.
+	return checkBaseline(built, opts)
+}
+
+// loadGraph resolves the input and builds the canonical graph for the selected source pipeline.
+func loadGraph(ctx context.Context, opts Options) (graph.Graph, error) {
+	resolved, err := resolveOptions(opts)
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	return buildResolvedGraph(ctx, opts, resolved)
+}
+
+// resolveOptions resolves the input selected by the path and mode options.
+func resolveOptions(opts Options) (inputresolve.Resolved, error) {
+	return resolveInput(inputresolve.Request{
 		Path:          opts.PackagePath,
 		Mode:          inputresolve.Mode(opts.Mode),
 		ProjectPath:   opts.ProjectPath,
 		WorkspacePath: opts.WorkspacePath,
 		BazelTargets:  opts.BazelTargets,
 	})
+}
+
+// buildResolvedGraph builds the canonical graph for a resolved input and applies the
+// graph-shaping options.
+func buildResolvedGraph(ctx context.Context, opts Options, resolved inputresolve.Resolved) (graph.Graph, error) {
+	g, err := buildSourceGraph(ctx, opts, resolved)
 	if err != nil {
-		return err
+		return graph.Graph{}, err
 	}
+	return transformGraph(g, opts)
+}
 
+// buildSourceGraph builds the canonical graph for a resolved input before any
+// graph-shaping option is applied.
+func buildSourceGraph(ctx context.Context, opts Options, resolved inputresolve.Resolved) (graph.Graph, error) {
 	var g graph.Graph
 	switch resolved.Mode {
 	case inputresolve.ModeSPM:
 		manifestJSON, err := dumpPackage(ctx, resolved.PackagePath)
 		if err != nil {
-			return err
+			return graph.Graph{}, err
 		}
 
 		pkg, err := decodeManifest(manifestJSON)
 		if err != nil {
-			return err
+			return graph.Graph{}, err
 		}
 
 		g, err = buildGraph(pkg, opts.IncludeTests)
 		if err != nil {
-			return apperrors.New(apperrors.KindRuntime, "failed to build dependency graph", err)
+			return graph.Graph{}, apperrors.New(apperrors.KindRuntime, "failed to build dependency graph", err)
 		}
 	case inputresolve.ModeXcode:
 		if resolved.TuistPath != "" {
 			if err := generateTuistProject(ctx, resolved.TuistPath); err != nil {
-				return err
+				return graph.Graph{}, err
 			}
 			generated, err := resolveInput(inputresolve.Request{Path: resolved.TuistPath, Mode: inputresolve.ModeXcode})
 			if err != nil {
-				return err
+				return graph.Graph{}, err
 			}
 			if generated.ProjectPath == "" {
-				return apperrors.New(apperrors.KindRuntime, fmt.Sprintf("tuist generation completed but no xcode project was resolved at %s", resolved.TuistPath), nil)
+				return graph.Graph{}, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("tuist generation completed but no xcode project was resolved at %s", resolved.TuistPath), nil)
 			}
 			resolved = generated
 		}
 		project, err := loadXcodeProject(ctx, resolved.ProjectPath)
 		if err != nil {
-			return err
+			return graph.Graph{}, err
 		}
 		g, err = buildXcodeGraph(project, opts.IncludeTests)
 		if err != nil {
-			return apperrors.New(apperrors.KindRuntime, "failed to build xcode dependency graph", err)
+			return graph.Graph{}, apperrors.New(apperrors.KindRuntime, "failed to build xcode dependency graph", err)
 		}
 	case inputresolve.ModeBazel:
 		workspace, err := loadBazelWorkspace(ctx, resolved.BazelWorkspacePath, resolved.BazelTargets)
 		if err != nil {
-			return err
+			return graph.Graph{}, err
 		}
 		g, err = buildBazelGraph(workspace, opts.IncludeTests)
 		if err != nil {
-			return apperrors.New(apperrors.KindRuntime, "failed to build bazel dependency graph", err)
+			return graph.Graph{}, apperrors.New(apperrors.KindRuntime, "failed to build bazel dependency graph", err)
 		}
 	default:
-		return apperrors.New(apperrors.KindInvalidArgs, "unsupported resolved input mode", nil)
+		return graph.Graph{}, apperrors.New(apperrors.KindInvalidArgs, "unsupported resolved input mode", nil)
+	}
+	return g, nil
+}
+
+// transformGraph applies the graph-shaping options to a freshly built graph. Node
+// filters run first so their patterns see the original product IDs.
+func transformGraph(g graph.Graph, opts Options) (graph.Graph, error) {
+	nodeFilter, err := filter.Parse(filterSpec(opts))
+	if err != nil {
+		return graph.Graph{}, err
+	}
+	g = nodeFilter.Apply(g)
+	if opts.HideExternal {
+		g = graph.HideExternal(g)
+	}
+	if opts.CollapsePackages {
+		g = graph.CollapsePackages(g)
 	}
+	return g, nil
+}
 
-	if opts.Format == "png" {
-		dotOut, err := renderDot(g)
+// emit renders g in the requested format and writes it to stdout, a file, or a Graphviz image.
+// When last is non-nil it holds the content previously emitted per artifact, and output
+// identical to it is not written again.
+func emit(ctx context.Context, g graph.Graph, opts Options, resolved inputresolve.Resolved, stdout io.Writer, last map[string]string) error {
+	if gvFormat, ok := graphvizFormats[opts.Format]; ok {
+		groups, err := nodeGroups(g, opts)
+		if err != nil {
+			return err
+		}
+		dotOut, err := renderDotOutput(g, opts, groups)
 		if err != nil {
 			return err
 		}
-		pngOutputPath := opts.OutputPath
-		if pngOutputPath == "" {
-			pngOutputPath = "deps.png"
+		imageOutputPath := opts.OutputPath
+		if imageOutputPath == "" {
+			imageOutputPath = "deps." + gvFormat
+		}
+		key := opts.Format + "=" + imageOutputPath
+		if sameAsLast(last, key, dotOut) {
+			return nil
 		}
-		if err := writePNG(ctx, dotOut, pngOutputPath); err != nil {
+		if err := exportGraphviz(ctx, graphviz.Request{Source: dotOut, OutputPath: imageOutputPath, Format: gvFormat, Engine: opts.Layout}); err != nil {
 			return err
 		}
-		logInfof("generated png using dot format at %s", absolutePath(pngOutputPath))
+		remember(last, key, dotOut)
+		logInfof("generated %s using dot format at %s", opts.Format, absolutePath(imageOutputPath))
 		return nil
 	}
 
-	rendered, err := renderTextOutput(g, opts.Format)
+	opts.Color = resolveColor(opts, stdout)
+	rendered, err := renderTextOutput(g, opts, resolved)
 	if err != nil {
 		return err
 	}
+	if opts.InjectPath != "" {
+		return inject(rendered, opts)
+	}
 
+	key := opts.Format + "=" + opts.OutputPath
+	if sameAsLast(last, key, rendered) {
+		return nil
+	}
 	if err := writeOutput(rendered, opts.OutputPath, stdout); err != nil {
 		return err
 	}
+	remember(last, key, rendered)
 	if opts.Verbose && opts.OutputPath != "" {
-		switch opts.Format {
-		case "mermaid":
-			logInfof("generated mermaid content at %s", opts.OutputPath)
-		case "dot":
-			logInfof("generated dot content at %s", opts.OutputPath)
-		case "terminal":
-			logInfof("generated terminal content at %s", opts.OutputPath)
-		}
+		logInfof("generated %s content at %s", opts.Format, opts.OutputPath)
 	}
 
 	return nil
 }
+
+// inject replaces the marked region of opts.InjectPath with rendered, or with Check set
+// fails when the region is out of date.
+func inject(rendered string, opts Options) error {
+	changed, err := injectOutput(injectBlock(opts.Format, rendered), opts.InjectPath, opts.Check)
+	if err != nil {
+		return err
+	}
+	switch {
+	case opts.Check && changed:
+		return apperrors.New(apperrors.KindOutputStale, fmt.Sprintf("%s diagram in %s is out of date; rerun with --inject %s", opts.Format, opts.InjectPath, opts.InjectPath), nil)
+	case changed:
+		logInfof("updated %s diagram in %s", opts.Format, opts.InjectPath)
+	case opts.Verbose:
+		logInfof("%s diagram in %s is up to date", opts.Format, opts.InjectPath)
+	}
+	return nil
+}
+
+// resolveColor turns the auto color mode into always or never: colors are used only when
+// writing to an interactive terminal and NO_COLOR is unset or empty.
+func resolveColor(opts Options, stdout io.Writer) string {
+	if opts.Color != "" && opts.Color != "auto" {
+		return opts.Color
+	}
+	if opts.OutputPath != "" || opts.InjectPath != "" || !isTerminal(stdout) {
+		return "never"
+	}
+	if value, ok := lookupEnv("NO_COLOR"); ok && value != "" {
+		return "never"
+	}
+	return "always"
+}
+
+// checkBaseline compares g with the stored baseline snapshot, or rewrites it when requested.
+func checkBaseline(g graph.Graph, opts Options) error {
+	if opts.BaselinePath == "" {
+		return nil
+	}
+	current := snapshotGraph(g)
+	if opts.UpdateBaseline {
+		if err := saveBaseline(opts.BaselinePath, current); err != nil {
+			return err
+		}
+		logInfof("updated baseline at %s", opts.BaselinePath)
+		return nil
+	}
+
+	stored, err := loadBaseline(opts.BaselinePath)
+	if err != nil {
+		return err
+	}
+	comparison := compareBaseline(stored, current)
+	if opts.Verbose {
+		logInfof(
+			"baseline %s: %d nodes added, %d removed; %d edges added, %d removed",
+			opts.BaselinePath,
+			len(comparison.AddedNodes), len(comparison.RemovedNodes),
+			len(comparison.AddedEdges), len(comparison.RemovedEdges),
+		)
+	}
+	if len(comparison.Violations) == 0 {
+		return nil
+	}
+
+	lines := append([]string(nil), comparison.Violations...)
+	for _, edge := range comparison.AddedEdges {
+		lines = append(lines, "new edge: "+edge.String())
+	}
+	return apperrors.New(
+		apperrors.KindBaselineExceeded,
+		fmt.Sprintf("dependency graph exceeds baseline %s:\n  %s", opts.BaselinePath, strings.Join(lines, "\n  ")),
+		nil,
+	)
+}
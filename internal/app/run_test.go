@@ -6,15 +6,26 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"swift-deps-diagram/internal/baseline"
 	"swift-deps-diagram/internal/bazel"
+	"swift-deps-diagram/internal/dsm"
 	apperrors "swift-deps-diagram/internal/errors"
 	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/graphviz"
 	"swift-deps-diagram/internal/inputresolve"
 	"swift-deps-diagram/internal/manifest"
+	"swift-deps-diagram/internal/metrics"
+	"swift-deps-diagram/internal/pins"
+	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/structurizr"
+	"swift-deps-diagram/internal/theme"
+	"swift-deps-diagram/internal/watch"
 	"swift-deps-diagram/internal/xcodeproj"
 )
 
@@ -32,7 +43,14 @@ type appHarness struct {
 	textOutput  string
 	pngPath     string
 	pngDot      string
+	imageFormat string
+	imageLayout string
 	logMessages []string
+	// injected records the block passed to injectOutput; injectChanged is its result.
+	injected      string
+	injectPath    string
+	injectCheck   bool
+	injectChanged bool
 }
 
 func stubAppDeps(t *testing.T) *appHarness {
@@ -51,9 +69,33 @@ func stubAppDeps(t *testing.T) *appHarness {
 	oldMermaid := renderMermaid
 	oldDot := renderDot
 	oldTerminal := renderTerminal
+	oldLoadTheme := loadTheme
+	oldHTML := renderHTML
+	oldPlantUML := renderPlantUML
+	oldD2 := renderD2
+	oldJSON := renderJSON
+	oldGraphML := renderGraphML
+	oldGEXF := renderGEXF
+	oldLoadMapping := loadStructurizrMapping
+	oldStructurizr := renderStructurizr
+	oldFindVersions := findPackageVersions
+	oldMarkdown := renderMarkdown
+	oldComputeMetrics := computeMetrics
+	oldMetricsText := renderMetricsText
+	oldMetricsJSON := renderMetricsJSON
+	oldDSMText := renderDSMText
+	oldDSMCSV := renderDSMCSV
+	oldDSMHTML := renderDSMHTML
 	oldWrite := writeOutput
-	oldWritePNG := writePNG
+	oldInject := injectOutput
+	oldWatchInputs := watchInputs
+	oldListenAndServe := listenAndServe
+	oldExportGraphviz := exportGraphviz
+	oldLoadBaseline := loadBaseline
+	oldSaveBaseline := saveBaseline
 	oldLogInfof := logInfof
+	oldIsTerminal := isTerminal
+	oldLookupEnv := lookupEnv
 	t.Cleanup(func() {
 		resolveInput = oldResolve
 		dumpPackage = oldDump
@@ -67,9 +109,33 @@ func stubAppDeps(t *testing.T) *appHarness {
 		renderMermaid = oldMermaid
 		renderDot = oldDot
 		renderTerminal = oldTerminal
+		loadTheme = oldLoadTheme
+		renderHTML = oldHTML
+		renderPlantUML = oldPlantUML
+		renderD2 = oldD2
+		renderJSON = oldJSON
+		renderGraphML = oldGraphML
+		renderGEXF = oldGEXF
+		loadStructurizrMapping = oldLoadMapping
+		renderStructurizr = oldStructurizr
+		findPackageVersions = oldFindVersions
+		renderMarkdown = oldMarkdown
+		computeMetrics = oldComputeMetrics
+		renderMetricsText = oldMetricsText
+		renderMetricsJSON = oldMetricsJSON
+		renderDSMText = oldDSMText
+		renderDSMCSV = oldDSMCSV
+		renderDSMHTML = oldDSMHTML
 		writeOutput = oldWrite
-		writePNG = oldWritePNG
+		injectOutput = oldInject
+		watchInputs = oldWatchInputs
+		listenAndServe = oldListenAndServe
+		exportGraphviz = oldExportGraphviz
+		loadBaseline = oldLoadBaseline
+		saveBaseline = oldSaveBaseline
 		logInfof = oldLogInfof
+		isTerminal = oldIsTerminal
+		lookupEnv = oldLookupEnv
 	})
 
 	resolveInput = func(req inputresolve.Request) (inputresolve.Resolved, error) {
@@ -91,18 +157,47 @@ func stubAppDeps(t *testing.T) *appHarness {
 	buildBazelGraph = func(bazel.Workspace, bool) (graph.Graph, error) {
 		return graph.Graph{Nodes: map[string]graph.Node{}, Edges: []graph.Edge{}}, nil
 	}
-	renderMermaid = func(graph.Graph) (string, error) { return "MERMAID", nil }
-	renderDot = func(graph.Graph) (string, error) { return "DOT", nil }
-	renderTerminal = func(graph.Graph) (string, error) { return "TERMINAL", nil }
+	renderMermaid = func(graph.Graph, render.MermaidOptions) (string, error) { return "MERMAID", nil }
+	renderDot = func(graph.Graph, render.DotOptions) (string, error) { return "DOT", nil }
+	renderTerminal = func(graph.Graph, render.TerminalOptions) (string, error) { return "TERMINAL", nil }
+	loadTheme = func(string) (theme.Theme, error) { return theme.Default(), nil }
+	isTerminal = func(io.Writer) bool { return false }
+	lookupEnv = func(string) (string, bool) { return "", false }
+	renderHTML = func(graph.Graph) (string, error) { return "HTML", nil }
+	renderPlantUML = func(graph.Graph, render.PlantUMLOptions) (string, error) { return "PLANTUML", nil }
+	renderD2 = func(graph.Graph, render.D2Options) (string, error) { return "D2", nil }
+	renderJSON = func(graph.Graph) (string, error) { return "JSON", nil }
+	renderGraphML = func(graph.Graph) (string, error) { return "GRAPHML", nil }
+	renderGEXF = func(graph.Graph) (string, error) { return "GEXF", nil }
+	findPackageVersions = func(string, string, string) (map[string]string, error) { return map[string]string{}, nil }
+	renderMarkdown = func(graph.Graph, render.MarkdownOptions) (string, error) { return "MARKDOWN", nil }
+	computeMetrics = func(graph.Graph) metrics.Report { return metrics.Report{} }
+	renderMetricsText = func(metrics.Report) (string, error) { return "METRICS", nil }
+	renderMetricsJSON = func(metrics.Report) (string, error) { return "METRICS_JSON", nil }
+	renderDSMText = func(dsm.Matrix) (string, error) { return "DSM", nil }
+	renderDSMCSV = func(dsm.Matrix) (string, error) { return "DSM_CSV", nil }
+	renderDSMHTML = func(dsm.Matrix) (string, error) { return "DSM_HTML", nil }
 	writeOutput = func(content, _ string, _ io.Writer) error {
 		h.textOutput = content
 		return nil
 	}
-	writePNG = func(_ context.Context, dotSource, outputPath string) error {
-		h.pngPath = outputPath
-		h.pngDot = dotSource
+	injectOutput = func(block, path string, check bool) (bool, error) {
+		h.injected = block
+		h.injectPath = path
+		h.injectCheck = check
+		return h.injectChanged, nil
+	}
+	watchInputs = func(context.Context, watch.Config, func() []string, func([]string)) error { return nil }
+	listenAndServe = func(context.Context, string, http.Handler) error { return nil }
+	exportGraphviz = func(_ context.Context, req graphviz.Request) error {
+		h.pngPath = req.OutputPath
+		h.pngDot = req.Source
+		h.imageFormat = req.Format
+		h.imageLayout = req.Engine
 		return nil
 	}
+	loadBaseline = func(string) (baseline.Snapshot, error) { return baseline.Snapshot{}, nil }
+	saveBaseline = func(string, baseline.Snapshot) error { return nil }
 	logInfof = func(format string, args ...interface{}) {
 		h.logMessages = append(h.logMessages, fmt.Sprintf(format, args...))
 	}
@@ -152,6 +247,339 @@ func TestRunTerminalModeWritesText(t *testing.T) {
 	}
 }
 
+func TestRunTerminalResolvesColorMode(t *testing.T) {
+	dir := withManifestDir(t)
+	cases := []struct {
+		name     string
+		color    string
+		output   string
+		tty      bool
+		noColor  string
+		expected bool
+	}{
+		{name: "auto_tty", color: "auto", tty: true, expected: true},
+		{name: "auto_pipe", color: "auto", tty: false, expected: false},
+		{name: "auto_file", color: "auto", output: "deps.tree", tty: true, expected: false},
+		{name: "auto_no_color", color: "auto", tty: true, noColor: "1", expected: false},
+		{name: "always_pipe", color: "always", tty: false, expected: true},
+		{name: "never_tty", color: "never", tty: true, expected: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stubAppDeps(t)
+			isTerminal = func(io.Writer) bool { return tc.tty }
+			lookupEnv = func(key string) (string, bool) {
+				if key == "NO_COLOR" && tc.noColor != "" {
+					return tc.noColor, true
+				}
+				return "", false
+			}
+			var got render.TerminalOptions
+			renderTerminal = func(_ graph.Graph, opts render.TerminalOptions) (string, error) {
+				got = opts
+				return "TERMINAL", nil
+			}
+
+			opts := Options{PackagePath: dir, Mode: "auto", Format: "terminal", OutputPath: tc.output, Color: tc.color, Unicode: true, MaxDepth: 3, Invert: true}
+			if err := Run(context.Background(), opts, &bytes.Buffer{}); err != nil {
+				t.Fatalf("unexpected run error: %v", err)
+			}
+			if got.Color != tc.expected || !got.Unicode || got.MaxDepth != 3 || !got.Invert {
+				t.Fatalf("expected color=%v with unicode, max depth 3, and invert, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunRejectsInvalidTerminalOptions(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+
+	for _, opts := range []Options{
+		{PackagePath: dir, Mode: "auto", Format: "terminal", Color: "sometimes"},
+		{PackagePath: dir, Mode: "auto", Format: "terminal", MaxDepth: -1},
+	} {
+		err := Run(context.Background(), opts, &bytes.Buffer{})
+		if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %#v, got %v", opts, err)
+		}
+	}
+}
+
+func TestRunHTMLFormatWritesPage(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "html", OutputPath: "deps.html"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.textOutput != "HTML" {
+		t.Fatalf("expected HTML output, got %q", h.textOutput)
+	}
+}
+
+func TestRunGraphExchangeFormatsWriteText(t *testing.T) {
+	dir := withManifestDir(t)
+
+	for format, expected := range map[string]string{"graphml": "GRAPHML", "gexf": "GEXF"} {
+		h := stubAppDeps(t)
+		err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: format}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("unexpected run error for %s: %v", format, err)
+		}
+		if h.textOutput != expected {
+			t.Fatalf("expected %s output, got %q", expected, h.textOutput)
+		}
+	}
+}
+
+func TestRunMarkdownUsesInputNameVersionsAndMermaidOptions(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+
+	var gotRoot string
+	findPackageVersions = func(root, projectPath, workspacePath string) (map[string]string, error) {
+		gotRoot = root
+		return map[string]string{"swift-nio": "2.62.0"}, nil
+	}
+	var got render.MarkdownOptions
+	renderMarkdown = func(_ graph.Graph, opts render.MarkdownOptions) (string, error) {
+		got = opts
+		return "MARKDOWN", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "markdown", Direction: "LR", LinkBase: "https://example.com/tree/main"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.textOutput != "MARKDOWN" || gotRoot != dir || got.Title != filepath.Base(dir) || got.Versions["swift-nio"] != "2.62.0" {
+		t.Fatalf("unexpected markdown call: output=%q root=%q opts=%#v", h.textOutput, gotRoot, got)
+	}
+	if got.Mermaid.Direction != "LR" || got.Mermaid.LinkBase != "https://example.com/tree/main" {
+		t.Fatalf("expected mermaid options to reach the report, got %#v", got.Mermaid)
+	}
+
+	findPackageVersions = func(string, string, string) (map[string]string, error) {
+		return nil, apperrors.New(apperrors.KindRuntime, "bad lockfile", nil)
+	}
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "markdown"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindRuntime) {
+		t.Fatalf("expected runtime error for unreadable lockfile, got %v", err)
+	}
+}
+
+func TestRunMarkdownUsesResolvedInputForManifestFilePath(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	resolveInput = inputresolve.Resolve
+	findPackageVersions = pins.Find
+	lockfile := `{"pins": [{"identity": "swift-nio", "kind": "remoteSourceControl", "location": "https://github.com/apple/swift-nio.git", "state": {"revision": "abc", "version": "2.62.0"}}], "version": 2}`
+	if err := os.WriteFile(filepath.Join(dir, pins.FileName), []byte(lockfile), 0o644); err != nil {
+		t.Fatalf("write lockfile: %v", err)
+	}
+	var got render.MarkdownOptions
+	renderMarkdown = func(_ graph.Graph, opts render.MarkdownOptions) (string, error) {
+		got = opts
+		return "MARKDOWN", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: filepath.Join(dir, "Package.swift"), Mode: "auto", Format: "markdown"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.textOutput != "MARKDOWN" || got.Title != filepath.Base(dir) || got.Versions["swift-nio"] != "2.62.0" {
+		t.Fatalf("expected the package directory to name the report and hold the lockfile, got %#v", got)
+	}
+
+	project := filepath.Join(dir, "ios", "App.xcodeproj")
+	if root := inputRoot(inputresolve.Resolved{Mode: inputresolve.ModeXcode, ProjectPath: project}); root != filepath.Join(dir, "ios") {
+		t.Fatalf("expected the project directory as Xcode root, got %q", root)
+	}
+	if root := inputRoot(inputresolve.Resolved{Mode: inputresolve.ModeXcode, TuistPath: dir}); root != dir {
+		t.Fatalf("expected the Tuist directory as root, got %q", root)
+	}
+}
+
+func TestRunInjectWrapsOutputInFencedBlock(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	h.injectChanged = true
+	isTerminal = func(io.Writer) bool { return true }
+	var gotColor bool
+	renderTerminal = func(_ graph.Graph, opts render.TerminalOptions) (string, error) {
+		gotColor = opts.Color
+		return "App\n\\-- Core\n", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "mermaid", InjectPath: "README.md"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.injected != "```mermaid\nMERMAID\n```" || h.injectPath != "README.md" || h.injectCheck || h.textOutput != "" {
+		t.Fatalf("unexpected inject call: block=%q path=%q check=%v output=%q", h.injected, h.injectPath, h.injectCheck, h.textOutput)
+	}
+	if len(h.logMessages) != 1 || h.logMessages[0] != "updated mermaid diagram in README.md" {
+		t.Fatalf("unexpected log messages: %v", h.logMessages)
+	}
+
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "terminal", Color: "auto", InjectPath: "README.md"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.injected != "```text\nApp\n\\-- Core\n```" || gotColor {
+		t.Fatalf("expected uncolored text block, got %q (color=%v)", h.injected, gotColor)
+	}
+
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "markdown", InjectPath: "README.md"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.injected != "MARKDOWN" {
+		t.Fatalf("expected markdown report injected as-is, got %q", h.injected)
+	}
+}
+
+func TestRunInjectCheckFailsWhenStale(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "mermaid", InjectPath: "README.md", Check: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("expected up-to-date check to pass, got %v", err)
+	}
+	if !h.injectCheck {
+		t.Fatalf("expected check mode to reach injectOutput")
+	}
+
+	h.injectChanged = true
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "mermaid", InjectPath: "README.md", Check: true}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindOutputStale) {
+		t.Fatalf("expected stale output error, got %v", err)
+	}
+}
+
+func TestRunInjectValidation(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+
+	for name, opts := range map[string]Options{
+		"check without inject": {Format: "mermaid", Check: true},
+		"image format":         {Format: "png", InjectPath: "README.md"},
+		"html format":          {Format: "html", InjectPath: "README.md"},
+		"with output":          {Format: "mermaid", OutputPath: "deps.mmd", InjectPath: "README.md"},
+		"with emit":            {Emit: []Artifact{{Format: "dot"}}, InjectPath: "README.md"},
+	} {
+		opts.PackagePath = dir
+		opts.Mode = "auto"
+		if err := Run(context.Background(), opts, &bytes.Buffer{}); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("%s: expected invalid args, got %v", name, err)
+		}
+	}
+}
+
+func TestRunStructurizrUsesMappingAndInputName(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+
+	var gotSystem string
+	var gotMapping structurizr.Mapping
+	loadStructurizrMapping = func(path string) (structurizr.Mapping, error) {
+		if path != "c4.json" {
+			t.Fatalf("unexpected mapping path %q", path)
+		}
+		return structurizr.Mapping{System: "Platform"}, nil
+	}
+	renderStructurizr = func(_ graph.Graph, m structurizr.Mapping, system string) (string, error) {
+		gotMapping = m
+		gotSystem = system
+		return "STRUCTURIZR", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "structurizr", StructurizrMapping: "c4.json"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.textOutput != "STRUCTURIZR" || gotMapping.System != "Platform" || gotSystem != filepath.Base(dir) {
+		t.Fatalf("unexpected structurizr call: output=%q mapping=%#v system=%q", h.textOutput, gotMapping, gotSystem)
+	}
+
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", StructurizrMapping: "c4.json"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for mapping without structurizr format, got %v", err)
+	}
+}
+
+func TestRunStructurizrNamesSystemAfterResolvedInput(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	var gotSystem string
+	renderStructurizr = func(_ graph.Graph, _ structurizr.Mapping, system string) (string, error) {
+		gotSystem = system
+		return "STRUCTURIZR", nil
+	}
+
+	resolveInput = inputresolve.Resolve
+	err := Run(context.Background(), Options{PackagePath: filepath.Join(dir, "Package.swift"), Mode: "auto", Format: "structurizr"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if gotSystem != filepath.Base(dir) {
+		t.Fatalf("expected the package directory to name the system, got %q", gotSystem)
+	}
+
+	project := filepath.Join(t.TempDir(), "Other", "App.xcodeproj")
+	resolveInput = func(inputresolve.Request) (inputresolve.Resolved, error) {
+		return inputresolve.Resolved{Mode: inputresolve.ModeXcode, ProjectPath: project}, nil
+	}
+	err = Run(context.Background(), Options{PackagePath: ".", ProjectPath: project, Mode: "auto", Format: "structurizr"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if gotSystem != "Other" {
+		t.Fatalf("expected the project directory to name the system, got %q", gotSystem)
+	}
+}
+
+func TestRunDSMFormatsWriteText(t *testing.T) {
+	dir := withManifestDir(t)
+
+	for format, expected := range map[string]string{"dsm": "DSM", "dsm-csv": "DSM_CSV", "dsm-html": "DSM_HTML"} {
+		h := stubAppDeps(t)
+		err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: format}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("unexpected run error for %s: %v", format, err)
+		}
+		if h.textOutput != expected {
+			t.Fatalf("expected %s output, got %q", expected, h.textOutput)
+		}
+	}
+}
+
+func TestRunMetricsFormatsWriteText(t *testing.T) {
+	dir := withManifestDir(t)
+
+	for format, expected := range map[string]string{"metrics": "METRICS", "metrics-json": "METRICS_JSON"} {
+		h := stubAppDeps(t)
+		computed := false
+		computeMetrics = func(graph.Graph) metrics.Report {
+			computed = true
+			return metrics.Report{}
+		}
+
+		err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: format}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("unexpected run error for %s: %v", format, err)
+		}
+		if !computed {
+			t.Fatalf("expected metrics to be computed for %s", format)
+		}
+		if h.textOutput != expected {
+			t.Fatalf("expected %s output, got %q", expected, h.textOutput)
+		}
+	}
+}
+
 func TestRunPNGModeUsesDefaultOutputPath(t *testing.T) {
 	dir := withManifestDir(t)
 	h := stubAppDeps(t)
@@ -442,3 +870,477 @@ func TestRunBazelModeUsesBazelPipeline(t *testing.T) {
 		t.Fatalf("expected DOT output, got %q", h.textOutput)
 	}
 }
+
+func TestRunBaselinePassesWhenGraphDidNotGrow(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":  {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::Core": {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+		},
+		Edges: []graph.Edge{{FromID: "target::App", ToID: "target::Core", Kind: graph.EdgeKindTarget}},
+	}
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) { return g, nil }
+	loadBaseline = func(path string) (baseline.Snapshot, error) {
+		if path != "deps-baseline.json" {
+			t.Fatalf("unexpected baseline path %q", path)
+		}
+		return baseline.FromGraph(g), nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", BaselinePath: "deps-baseline.json"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if h.textOutput != "DOT" {
+		t.Fatalf("expected output to still be written, got %q", h.textOutput)
+	}
+}
+
+func TestRunBaselineFailsWhenGraphGrows(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	base := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App": {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+		},
+	}
+	grown := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":      {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"pkg::x::Analytic": {ID: "pkg::x::Analytic", Label: "Analytic", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{{FromID: "target::App", ToID: "pkg::x::Analytic", Kind: graph.EdgeKindProduct}},
+	}
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) { return grown, nil }
+	loadBaseline = func(string) (baseline.Snapshot, error) { return baseline.FromGraph(base), nil }
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", BaselinePath: "deps-baseline.json"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindBaselineExceeded) {
+		t.Fatalf("expected baseline exceeded kind, got %v", err)
+	}
+	for _, part := range []string{"edge count 1 exceeds baseline 0", "new edge: target::App -> pkg::x::Analytic (product)"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Fatalf("expected %q in error, got %q", part, err.Error())
+		}
+	}
+}
+
+func TestRunBaselineMeasuresGraphBeforeTransforms(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	base := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App": {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+		},
+	}
+	grown := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":      {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"pkg::x::Analytic": {ID: "pkg::x::Analytic", Label: "Analytic", Kind: graph.NodeKindExternalProduct, Package: "x"},
+		},
+		Edges: []graph.Edge{{FromID: "target::App", ToID: "pkg::x::Analytic", Kind: graph.EdgeKindProduct}},
+	}
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) { return grown, nil }
+	loadBaseline = func(string) (baseline.Snapshot, error) { return baseline.FromGraph(base), nil }
+	var rendered graph.Graph
+	renderDot = func(g graph.Graph, _ render.DotOptions) (string, error) {
+		rendered = g
+		return "DOT", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", HideExternal: true, BaselinePath: "deps-baseline.json"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindBaselineExceeded) || !strings.Contains(err.Error(), "external product count 1 exceeds baseline 0") {
+		t.Fatalf("expected the hidden external product to exceed the baseline, got %v", err)
+	}
+	if len(rendered.Nodes) != 1 || h.textOutput != "DOT" {
+		t.Fatalf("expected the output to still hide external products, got %#v", rendered)
+	}
+
+	var saved baseline.Snapshot
+	saveBaseline = func(_ string, snapshot baseline.Snapshot) error {
+		saved = snapshot
+		return nil
+	}
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", CollapsePackages: true, BaselinePath: "deps-baseline.json", UpdateBaseline: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if len(saved.Nodes) != 2 || saved.Nodes[0] != "pkg::x::Analytic" {
+		t.Fatalf("expected the snapshot to keep product IDs, got %#v", saved.Nodes)
+	}
+}
+
+func TestRunUpdateBaselineWritesSnapshot(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	loadBaseline = func(string) (baseline.Snapshot, error) {
+		t.Fatal("did not expect baseline to be loaded when updating")
+		return baseline.Snapshot{}, nil
+	}
+	savedPath := ""
+	saveBaseline = func(path string, _ baseline.Snapshot) error {
+		savedPath = path
+		return nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", BaselinePath: "deps-baseline.json", UpdateBaseline: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if savedPath != "deps-baseline.json" {
+		t.Fatalf("expected baseline to be saved, got %q", savedPath)
+	}
+	if len(h.logMessages) != 1 || h.logMessages[0] != "updated baseline at deps-baseline.json" {
+		t.Fatalf("unexpected messages %#v", h.logMessages)
+	}
+}
+
+func TestRunUpdateBaselineRequiresPath(t *testing.T) {
+	err := Run(context.Background(), Options{PackagePath: ".", Mode: "auto", Format: "dot", UpdateBaseline: true}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+}
+
+func TestRunAppliesGraphTransforms(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) {
+		return graph.Graph{
+			Nodes: map[string]graph.Node{
+				"target::App":    {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+				"pkg::nio::NIO":  {ID: "pkg::nio::NIO", Label: "NIO", Kind: graph.NodeKindExternalProduct, Package: "nio"},
+				"pkg::nio::HTTP": {ID: "pkg::nio::HTTP", Label: "HTTP", Kind: graph.NodeKindExternalProduct, Package: "nio"},
+			},
+			Edges: []graph.Edge{
+				{FromID: "target::App", ToID: "pkg::nio::NIO", Kind: graph.EdgeKindProduct},
+				{FromID: "target::App", ToID: "pkg::nio::HTTP", Kind: graph.EdgeKindProduct},
+			},
+		}, nil
+	}
+
+	var rendered graph.Graph
+	renderDot = func(g graph.Graph, _ render.DotOptions) (string, error) {
+		rendered = g
+		return "DOT", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", CollapsePackages: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if len(rendered.Nodes) != 2 || len(rendered.Edges) != 1 || rendered.Edges[0].Weight != 2 {
+		t.Fatalf("expected collapsed graph, got %#v", rendered)
+	}
+
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", HideExternal: true, CollapsePackages: true}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if len(rendered.Nodes) != 1 || len(rendered.Edges) != 0 {
+		t.Fatalf("expected external nodes hidden, got %#v", rendered)
+	}
+}
+
+func TestRunAppliesNodeFilters(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) {
+		return graph.Graph{
+			Nodes: map[string]graph.Node{
+				"target::App":      {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+				"target::AppMocks": {ID: "target::AppMocks", Label: "AppMocks", Kind: graph.NodeKindTarget},
+				"target::Core":     {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+				"pkg::nio::NIO":    {ID: "pkg::nio::NIO", Label: "NIO", Kind: graph.NodeKindExternalProduct, Package: "nio"},
+			},
+			Edges: []graph.Edge{
+				{FromID: "target::App", ToID: "target::AppMocks", Kind: graph.EdgeKindTarget},
+				{FromID: "target::AppMocks", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+				{FromID: "target::Core", ToID: "pkg::nio::NIO", Kind: graph.EdgeKindProduct},
+			},
+		}, nil
+	}
+
+	var rendered graph.Graph
+	renderDot = func(g graph.Graph, _ render.DotOptions) (string, error) {
+		rendered = g
+		return "DOT", nil
+	}
+
+	opts := Options{PackagePath: dir, Mode: "auto", Format: "dot", Exclude: []string{"*Mocks"}, ExcludeKinds: []string{"external_product"}}
+	if err := Run(context.Background(), opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if len(rendered.Nodes) != 2 || len(rendered.Edges) != 0 {
+		t.Fatalf("expected mocks and external products dropped, got %#v", rendered)
+	}
+
+	opts.FilterMode = "bridge"
+	if err := Run(context.Background(), opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if len(rendered.Edges) != 1 || rendered.Edges[0].FromID != "target::App" || rendered.Edges[0].ToID != "target::Core" {
+		t.Fatalf("expected App bridged to Core, got %#v", rendered.Edges)
+	}
+
+	opts.Exclude = []string{"regex:("}
+	if err := Run(context.Background(), opts, &bytes.Buffer{}); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for a bad pattern, got %v", err)
+	}
+}
+
+func TestRunPassesClusterGroupsToRenderers(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) {
+		return graph.Graph{Nodes: map[string]graph.Node{
+			"target::App": {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget, Package: "Sample"},
+		}}, nil
+	}
+
+	var dotGroups, mermaidGroups, plantUMLGroups, d2Groups map[string]string
+	renderDot = func(_ graph.Graph, opts render.DotOptions) (string, error) {
+		dotGroups = opts.Groups
+		return "DOT", nil
+	}
+	renderMermaid = func(_ graph.Graph, opts render.MermaidOptions) (string, error) {
+		mermaidGroups = opts.Groups
+		return "MERMAID", nil
+	}
+	renderPlantUML = func(_ graph.Graph, opts render.PlantUMLOptions) (string, error) {
+		plantUMLGroups = opts.Groups
+		return "PLANTUML", nil
+	}
+	renderD2 = func(_ graph.Graph, opts render.D2Options) (string, error) {
+		d2Groups = opts.Groups
+		return "D2", nil
+	}
+
+	for _, format := range []string{"dot", "png", "mermaid", "plantuml", "d2"} {
+		err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: format, ClusterBy: "package"}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("unexpected run error for %s: %v", format, err)
+		}
+	}
+	if dotGroups["target::App"] != "Sample" || mermaidGroups["target::App"] != "Sample" || plantUMLGroups["target::App"] != "Sample" || d2Groups["target::App"] != "Sample" {
+		t.Fatalf("expected package groups, got dot=%#v mermaid=%#v plantuml=%#v d2=%#v", dotGroups, mermaidGroups, plantUMLGroups, d2Groups)
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "dot", ClusterBy: "module"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for unknown rule, got %v", err)
+	}
+}
+
+func TestRunPassesMermaidDirectionAndLinks(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	var got render.MermaidOptions
+	renderMermaid = func(_ graph.Graph, opts render.MermaidOptions) (string, error) {
+		got = opts
+		return "MERMAID", nil
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "mermaid", Direction: "LR", LinkBase: "https://example.com/repo"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if got.Direction != "LR" || got.LinkBase != "https://example.com/repo" {
+		t.Fatalf("expected direction and link base, got %#v", got)
+	}
+
+	err = Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "mermaid", Direction: "UP"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind, got %v", err)
+	}
+}
+
+func TestRunAppliesThemeToDotAndPNG(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	dark, _ := theme.Preset("dark")
+	var specs []string
+	loadTheme = func(spec string) (theme.Theme, error) {
+		specs = append(specs, spec)
+		return dark, nil
+	}
+	var themes []*theme.Theme
+	renderDot = func(_ graph.Graph, opts render.DotOptions) (string, error) {
+		themes = append(themes, opts.Theme)
+		return "DOT", nil
+	}
+
+	for _, format := range []string{"dot", "png"} {
+		if err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: format, Theme: "dark"}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("unexpected run error for %s: %v", format, err)
+		}
+	}
+	if len(specs) != 2 || specs[0] != "dark" || specs[1] != "dark" {
+		t.Fatalf("expected theme spec for dot and png, got %v", specs)
+	}
+	if len(themes) != 2 || themes[0] == nil || themes[1] == nil || themes[1].Graph["bgcolor"] != "#0d1117" {
+		t.Fatalf("expected loaded theme passed to dot renderer, got %#v", themes)
+	}
+
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "mermaid", Theme: "dark"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind for mermaid theme, got %v", err)
+	}
+}
+
+func TestRunGraphvizFormatsExportImages(t *testing.T) {
+	dir := withManifestDir(t)
+	cases := []struct {
+		format     string
+		gvFormat   string
+		outputPath string
+	}{
+		{format: "svg", gvFormat: "svg", outputPath: "deps.svg"},
+		{format: "pdf", gvFormat: "pdf", outputPath: "deps.pdf"},
+		{format: "jpg", gvFormat: "jpg", outputPath: "deps.jpg"},
+		{format: "webp", gvFormat: "webp", outputPath: "deps.webp"},
+		{format: "graphviz-json", gvFormat: "json", outputPath: "deps.json"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.format, func(t *testing.T) {
+			h := stubAppDeps(t)
+			err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: tc.format, Layout: "neato"}, &bytes.Buffer{})
+			if err != nil {
+				t.Fatalf("unexpected run error: %v", err)
+			}
+			if h.pngPath != tc.outputPath || h.imageFormat != tc.gvFormat || h.imageLayout != "neato" || h.pngDot != "DOT" {
+				t.Fatalf("unexpected export: path=%q format=%q layout=%q source=%q", h.pngPath, h.imageFormat, h.imageLayout, h.pngDot)
+			}
+			if h.textOutput != "" {
+				t.Fatalf("expected no text output, got %q", h.textOutput)
+			}
+		})
+	}
+}
+
+func TestRunRejectsInvalidLayout(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+
+	for _, opts := range []Options{
+		{PackagePath: dir, Mode: "auto", Format: "svg", Layout: "spring"},
+		{PackagePath: dir, Mode: "auto", Format: "dot", Layout: "neato"},
+	} {
+		err := Run(context.Background(), opts, &bytes.Buffer{})
+		if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %#v, got %v", opts, err)
+		}
+	}
+}
+
+func TestFormatForOutput(t *testing.T) {
+	cases := map[string]string{
+		"docs/deps.svg": "svg",
+		"deck/deps.pdf": "pdf",
+		"deps.jpeg":     "jpg",
+		"deps.json":     "",
+		"deps.gv":       "dot",
+		"deps.dot":      "dot",
+		"deps.md":       "",
+	}
+	for path, want := range cases {
+		got, ok := FormatForOutput(path)
+		if got != want || ok != (want != "") {
+			t.Fatalf("FormatForOutput(%q) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+}
+
+func TestRunEmitRendersEveryArtifactFromOneGraph(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+	builds := 0
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) {
+		builds++
+		return graph.Graph{Nodes: map[string]graph.Node{}, Edges: []graph.Edge{}}, nil
+	}
+	written := make(map[string]string)
+	writeOutput = func(content, outputPath string, _ io.Writer) error {
+		written[outputPath] = content
+		return nil
+	}
+
+	opts := Options{
+		PackagePath: dir,
+		Mode:        "auto",
+		Format:      "terminal",
+		Emit: []Artifact{
+			{Format: "mermaid", OutputPath: "docs/deps.md"},
+			{Format: "png", OutputPath: "docs/deps.png"},
+			{Format: "json", OutputPath: "build/deps.json"},
+			{Format: "dot"},
+		},
+	}
+	if err := Run(context.Background(), opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if builds != 1 {
+		t.Fatalf("expected the graph to be built once, got %d", builds)
+	}
+	if written["docs/deps.md"] != "MERMAID" || written["build/deps.json"] != "JSON" || written[""] != "DOT" || len(written) != 3 {
+		t.Fatalf("unexpected text artifacts: %#v", written)
+	}
+	if h.pngPath != "docs/deps.png" || h.imageFormat != "png" {
+		t.Fatalf("expected png artifact, got path=%q format=%q", h.pngPath, h.imageFormat)
+	}
+}
+
+func TestRunEmitRejectsConflictingArtifacts(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+
+	for _, emit := range [][]Artifact{
+		{{Format: "mermaid"}, {Format: "dot"}},
+		{{Format: "mermaid", OutputPath: "deps.txt"}, {Format: "dot", OutputPath: "./deps.txt"}},
+		{{Format: "png"}, {Format: "png"}},
+		{{Format: "bogus", OutputPath: "deps.txt"}},
+	} {
+		err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Format: "png", Emit: emit}, &bytes.Buffer{})
+		if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %#v, got %v", emit, err)
+		}
+	}
+}
+
+func TestRunEmitValidatesFormatSpecificOptionsAgainstAnyArtifact(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+
+	emit := []Artifact{{Format: "mermaid", OutputPath: "deps.md"}, {Format: "svg", OutputPath: "deps.svg"}}
+	if err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Emit: emit, Theme: "dark", Layout: "neato"}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	err := Run(context.Background(), Options{PackagePath: dir, Mode: "auto", Emit: emit[:1], Layout: "neato"}, &bytes.Buffer{})
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind without a Graphviz artifact, got %v", err)
+	}
+}
+
+func TestParseEmit(t *testing.T) {
+	got, err := ParseEmit("mermaid=docs/deps.md, png=docs/deps.png,json=build/deps.json,terminal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Artifact{
+		{Format: "mermaid", OutputPath: "docs/deps.md"},
+		{Format: "png", OutputPath: "docs/deps.png"},
+		{Format: "json", OutputPath: "build/deps.json"},
+		{Format: "terminal"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("unexpected artifacts: %#v", got)
+	}
+
+	for _, spec := range []string{"", ",", "bmp=deps.bmp"} {
+		if _, err := ParseEmit(spec); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %q, got %v", spec, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"swift-deps-diagram/internal/cluster"
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/graphviz"
+	"swift-deps-diagram/internal/inputresolve"
+	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/server"
+	"swift-deps-diagram/internal/watch"
+)
+
+// DefaultServeAddr is the address the serve subcommand listens on by default.
+const DefaultServeAddr = "127.0.0.1:8080"
+
+// listenAndServe serves handler on addr until ctx is done.
+var listenAndServe = func(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed serving on %s", addr), err)
+	}
+	return nil
+}
+
+// ServeOptions configure one serve subcommand run.
+type ServeOptions struct {
+	// Addr is the host:port to listen on; empty means DefaultServeAddr.
+	Addr string
+}
+
+// RunServe builds the graph, serves it over HTTP, and rebuilds it whenever an input
+// manifest changes, until ctx is done. A failed rebuild keeps the last good graph.
+func RunServe(ctx context.Context, opts Options, serve ServeOptions, stdout io.Writer) error {
+	if err := validateServeOptions(opts); err != nil {
+		return err
+	}
+	addr := serve.Addr
+	if addr == "" {
+		addr = DefaultServeAddr
+	}
+	resolved, err := resolveOptions(opts)
+	if err != nil {
+		return err
+	}
+	g, err := buildResolvedGraph(ctx, opts, resolved)
+	if err != nil {
+		return err
+	}
+
+	srv := server.New(func(ctx context.Context, g graph.Graph, format string) ([]byte, error) {
+		return renderServed(ctx, g, withArtifact(opts, Artifact{Format: format}), resolved)
+	})
+	srv.Update(g)
+
+	logInfof("serving dependency graph at http://%s", addr)
+	ctx, cancel := context.WithCancel(ctx)
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		_ = watchInputs(ctx, watchConfig, func() []string { return watch.Files(resolved) }, func(changed []string) {
+			g, err := buildResolvedGraph(ctx, opts, resolved)
+			if err != nil {
+				srv.Fail(err)
+				logInfof("error: %v", err)
+				return
+			}
+			srv.Update(g)
+			logInfof("%s changed; graph reloaded", strings.Join(changed, ", "))
+		})
+	}()
+
+	err = listenAndServe(ctx, addr, srv)
+	cancel()
+	<-watched
+	return err
+}
+
+// validateServeOptions checks the input options and the rendering options that apply
+// to every served format.
+func validateServeOptions(opts Options) error {
+	if err := validateInputOptions(opts); err != nil {
+		return err
+	}
+	if _, err := cluster.Parse(opts.ClusterBy); err != nil {
+		return err
+	}
+	if _, err := loadTheme(opts.Theme); err != nil {
+		return err
+	}
+	if opts.Layout != "" && !graphviz.IsEngine(opts.Layout) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--layout must be one of: "+LayoutUsage(), nil)
+	}
+	if opts.Direction != "" && !render.IsValidMermaidDirection(opts.Direction) {
+		return apperrors.New(apperrors.KindInvalidArgs, "--direction must be one of: "+strings.Join(render.MermaidDirections, "|"), nil)
+	}
+	return nil
+}
+
+// renderServed renders g for one HTTP request. Graphviz formats are exported to a
+// temporary file and read back.
+func renderServed(ctx context.Context, g graph.Graph, opts Options, resolved inputresolve.Resolved) ([]byte, error) {
+	opts.Color = "never"
+	gvFormat, ok := graphvizFormats[opts.Format]
+	if !ok {
+		rendered, err := renderTextOutput(g, opts, resolved)
+		return []byte(rendered), err
+	}
+
+	groups, err := nodeGroups(g, opts)
+	if err != nil {
+		return nil, err
+	}
+	dotOut, err := renderDotOutput(g, opts, groups)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "swift-deps-diagram-serve-*")
+	if err != nil {
+		return nil, apperrors.New(apperrors.KindOutputWrite, "failed creating temp directory", err)
+	}
+	defer os.RemoveAll(dir)
+	imagePath := filepath.Join(dir, "graph."+gvFormat)
+	if err := exportGraphviz(ctx, graphviz.Request{Source: dotOut, OutputPath: imagePath, Format: gvFormat, Engine: opts.Layout}); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		return nil, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading rendered %s", opts.Format), err)
+	}
+	return data, nil
+}
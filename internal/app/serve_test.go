@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/graphviz"
+	"swift-deps-diagram/internal/manifest"
+	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/watch"
+)
+
+func TestRunServeServesGraphAndReloadsOnChange(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+
+	builds := 0
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) {
+		builds++
+		nodes := map[string]graph.Node{"target::App": {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget}}
+		if builds > 1 {
+			nodes["target::Core"] = graph.Node{ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget}
+		}
+		return graph.Graph{Nodes: nodes, Edges: []graph.Edge{}}, nil
+	}
+	var gotDirection string
+	renderMermaid = func(g graph.Graph, opts render.MermaidOptions) (string, error) {
+		gotDirection = opts.Direction
+		return strings.Join(graph.SortedNodeIDs(g), ","), nil
+	}
+	exportGraphviz = func(_ context.Context, req graphviz.Request) error {
+		return os.WriteFile(req.OutputPath, []byte("<svg>"+req.Source+"</svg>"), 0o644)
+	}
+
+	reloaded := make(chan struct{})
+	watchInputs = func(ctx context.Context, _ watch.Config, _ func() []string, onChange func([]string)) error {
+		onChange([]string{"Package.swift"})
+		close(reloaded)
+		<-ctx.Done()
+		return nil
+	}
+	var gotAddr string
+	responses := make(map[string]string)
+	listenAndServe = func(_ context.Context, addr string, handler http.Handler) error {
+		gotAddr = addr
+		<-reloaded
+		for _, target := range []string{"/graph.mmd", "/graph.mmd?focus=Core", "/graph.svg"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("GET %s: status %d (%s)", target, rec.Code, rec.Body.String())
+			}
+			responses[target] = rec.Body.String()
+		}
+		return nil
+	}
+
+	err := RunServe(context.Background(), Options{PackagePath: dir, Mode: "auto", Direction: "LR"}, ServeOptions{}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("unexpected serve error: %v", err)
+	}
+	if gotAddr != DefaultServeAddr || builds != 2 {
+		t.Fatalf("unexpected serve run: addr=%q builds=%d", gotAddr, builds)
+	}
+	if responses["/graph.mmd"] != "target::App,target::Core" || responses["/graph.mmd?focus=Core"] != "target::Core" || gotDirection != "LR" {
+		t.Fatalf("unexpected mermaid responses %#v (direction %q)", responses, gotDirection)
+	}
+	if responses["/graph.svg"] != "<svg>DOT</svg>" {
+		t.Fatalf("unexpected svg response %q", responses["/graph.svg"])
+	}
+	logs := strings.Join(h.logMessages, "\n")
+	if !strings.Contains(logs, "serving dependency graph at http://"+DefaultServeAddr) || !strings.Contains(logs, "Package.swift changed; graph reloaded") {
+		t.Fatalf("unexpected log messages:\n%s", logs)
+	}
+}
+
+func TestRunServeFailsWhenInitialGraphFails(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+	decodeManifest = func([]byte) (manifest.Package, error) {
+		return manifest.Package{}, apperrors.New(apperrors.KindManifestDecode, "bad manifest", nil)
+	}
+	listenAndServe = func(context.Context, string, http.Handler) error {
+		t.Fatal("server must not start without a graph")
+		return nil
+	}
+
+	err := RunServe(context.Background(), Options{PackagePath: dir, Mode: "auto"}, ServeOptions{Addr: ":0"}, io.Discard)
+	if !apperrors.IsKind(err, apperrors.KindManifestDecode) {
+		t.Fatalf("expected manifest decode error, got %v", err)
+	}
+
+	err = RunServe(context.Background(), Options{PackagePath: dir, Mode: "auto", ClusterBy: "bogus"}, ServeOptions{}, io.Discard)
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for bad cluster rule, got %v", err)
+	}
+}
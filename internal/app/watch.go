@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"swift-deps-diagram/internal/inputresolve"
+	"swift-deps-diagram/internal/watch"
+)
+
+var watchInputs = watch.Loop
+
+// watchConfig sets how often --watch polls the input manifests and how long they must
+// stay unchanged before the outputs are regenerated.
+var watchConfig = watch.Config{Interval: 500 * time.Millisecond, Debounce: 300 * time.Millisecond}
+
+// runWatch generates the outputs once and again whenever a manifest of the resolved
+// input changes, until ctx is done. Failed regenerations are logged and watching
+// continues, so a half-edited manifest does not end the session.
+func runWatch(ctx context.Context, opts Options, resolved inputresolve.Resolved, stdout io.Writer) error {
+	last := make(map[string]string)
+	regenerate := func() {
+		if err := generate(ctx, opts, resolved, stdout, last); err != nil {
+			logInfof("error: %v", err)
+		}
+	}
+
+	regenerate()
+	files := func() []string { return watch.Files(resolved) }
+	logInfof("watching %d input files for changes", len(files()))
+	return watchInputs(ctx, watchConfig, files, func(changed []string) {
+		logInfof("%s changed; regenerating", strings.Join(changed, ", "))
+		regenerate()
+	})
+}
+
+// sameAsLast reports whether content equals what was last emitted for key. A nil last
+// map never matches, so one-shot runs always write.
+func sameAsLast(last map[string]string, key, content string) bool {
+	if last == nil {
+		return false
+	}
+	previous, ok := last[key]
+	return ok && previous == content
+}
+
+// remember records content as the last output emitted for key.
+func remember(last map[string]string, key, content string) {
+	if last != nil {
+		last[key] = content
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/graphviz"
+	"swift-deps-diagram/internal/manifest"
+	"swift-deps-diagram/internal/render"
+	"swift-deps-diagram/internal/watch"
+)
+
+func TestRunWatchRegeneratesOnlyChangedOutputs(t *testing.T) {
+	dir := withManifestDir(t)
+	h := stubAppDeps(t)
+
+	builds := 0
+	buildGraph = func(manifest.Package, bool) (graph.Graph, error) {
+		builds++
+		if builds == 3 {
+			return graph.Graph{}, apperrors.New(apperrors.KindManifestDecode, "half-edited manifest", nil)
+		}
+		nodes := map[string]graph.Node{"target::App": {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget}}
+		if builds >= 4 {
+			nodes["target::Core"] = graph.Node{ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget}
+		}
+		return graph.Graph{Nodes: nodes}, nil
+	}
+	renderMermaid = func(g graph.Graph, _ render.MermaidOptions) (string, error) {
+		return strings.Join(graph.SortedNodeIDs(g), ","), nil
+	}
+	renderDot = func(g graph.Graph, _ render.DotOptions) (string, error) {
+		return "digraph " + strings.Join(graph.SortedNodeIDs(g), ","), nil
+	}
+	writes := 0
+	writeOutput = func(content, _ string, _ io.Writer) error {
+		writes++
+		h.textOutput = content
+		return nil
+	}
+	exports := 0
+	exportGraphviz = func(context.Context, graphviz.Request) error {
+		exports++
+		return nil
+	}
+
+	var watched []string
+	watchInputs = func(_ context.Context, cfg watch.Config, list func() []string, onChange func([]string)) error {
+		if cfg.Interval <= 0 {
+			t.Fatalf("expected a polling interval, got %#v", cfg)
+		}
+		watched = list()
+		for i := 0; i < 3; i++ {
+			onChange([]string{"Package.swift"})
+		}
+		return nil
+	}
+
+	opts := Options{PackagePath: dir, Mode: "auto", Watch: true, Emit: []Artifact{{Format: "mermaid", OutputPath: "deps.mmd"}, {Format: "svg", OutputPath: "deps.svg"}}}
+	if err := Run(context.Background(), opts, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+	if builds != 4 {
+		t.Fatalf("expected the initial build plus one per change, got %d", builds)
+	}
+	if writes != 2 || exports != 2 || h.textOutput != "target::App,target::Core" {
+		t.Fatalf("expected unchanged outputs to be skipped: writes=%d exports=%d output=%q", writes, exports, h.textOutput)
+	}
+	if len(watched) != 1 || watched[0] != filepath.Join(dir, "Package.swift") {
+		t.Fatalf("unexpected watched files %v", watched)
+	}
+	logs := strings.Join(h.logMessages, "\n")
+	if !strings.Contains(logs, "watching 1 input files for changes") || !strings.Contains(logs, "error: failed to build dependency graph: half-edited manifest") {
+		t.Fatalf("unexpected log messages:\n%s", logs)
+	}
+}
+
+func TestRunWatchRejectsCheckAndUpdateBaseline(t *testing.T) {
+	dir := withManifestDir(t)
+	stubAppDeps(t)
+
+	for _, opts := range []Options{
+		{PackagePath: dir, Mode: "auto", Format: "mermaid", Watch: true, InjectPath: "README.md", Check: true},
+		{PackagePath: dir, Mode: "auto", Format: "mermaid", Watch: true, BaselinePath: "base.json", UpdateBaseline: true},
+	} {
+		if err := Run(context.Background(), opts, &bytes.Buffer{}); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args for %#v, got %v", opts, err)
+		}
+	}
+}
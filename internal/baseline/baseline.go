@@ -0,0 +1,144 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/metrics"
+	"swift-deps-diagram/internal/output"
+)
+
+// Snapshot is the stored state of a dependency graph used as a ratchet in CI.
+type Snapshot struct {
+	Nodes   []string       `json:"nodes"`
+	Edges   []Edge         `json:"edges"`
+	Metrics metrics.Report `json:"metrics"`
+}
+
+// Edge is the serialized form of a graph edge inside a snapshot.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Comparison describes how the current graph differs from a snapshot.
+type Comparison struct {
+	AddedNodes   []string
+	RemovedNodes []string
+	AddedEdges   []Edge
+	RemovedEdges []Edge
+	// Violations lists ratchet rules the current graph breaks.
+	Violations []string
+}
+
+func (e Edge) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", e.From, e.To, e.Kind)
+}
+
+// FromGraph captures nodes, edges and metrics of g in a snapshot.
+func FromGraph(g graph.Graph) Snapshot {
+	s := Snapshot{
+		Nodes:   graph.SortedNodeIDs(g),
+		Edges:   make([]Edge, 0, len(g.Edges)),
+		Metrics: metrics.Compute(g),
+	}
+	for _, edge := range graph.SortedEdges(g) {
+		s.Edges = append(s.Edges, Edge{From: edge.FromID, To: edge.ToID, Kind: string(edge.Kind)})
+	}
+	return s
+}
+
+// Load reads a snapshot from a JSON file.
+func Load(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Snapshot{}, apperrors.New(apperrors.KindInputNotFound, fmt.Sprintf("baseline not found at %s (run with --update-baseline to create it)", path), err)
+		}
+		return Snapshot{}, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading baseline %s", path), err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed to decode baseline %s", path), err)
+	}
+	return s, nil
+}
+
+// Save atomically writes a snapshot as indented JSON.
+func Save(path string, s Snapshot) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return apperrors.New(apperrors.KindRuntime, "failed to encode baseline", err)
+	}
+	return output.Write(string(data)+"\n", path, io.Discard)
+}
+
+// Compare checks current against base. The edge count, the number of external
+// products, and the fan-out of every target already present in base may not grow.
+func Compare(base, current Snapshot) Comparison {
+	c := Comparison{
+		AddedNodes:   diffStrings(current.Nodes, base.Nodes),
+		RemovedNodes: diffStrings(base.Nodes, current.Nodes),
+		AddedEdges:   diffEdges(current.Edges, base.Edges),
+		RemovedEdges: diffEdges(base.Edges, current.Edges),
+	}
+
+	if got, limit := len(current.Edges), len(base.Edges); got > limit {
+		c.Violations = append(c.Violations, fmt.Sprintf("edge count %d exceeds baseline %d", got, limit))
+	}
+	if got, limit := current.Metrics.Summary.ExternalProducts, base.Metrics.Summary.ExternalProducts; got > limit {
+		c.Violations = append(c.Violations, fmt.Sprintf("external product count %d exceeds baseline %d", got, limit))
+	}
+
+	baseFanOut := make(map[string]int, len(base.Metrics.Targets))
+	for _, target := range base.Metrics.Targets {
+		baseFanOut[target.ID] = target.FanOut
+	}
+	for _, target := range current.Metrics.Targets {
+		limit, ok := baseFanOut[target.ID]
+		if !ok || target.FanOut <= limit {
+			continue
+		}
+		c.Violations = append(c.Violations, fmt.Sprintf("fan-out of %s is %d, exceeds baseline %d", target.Label, target.FanOut, limit))
+	}
+
+	return c
+}
+
+func diffStrings(a, b []string) []string {
+	inB := make(map[string]struct{}, len(b))
+	for _, value := range b {
+		inB[value] = struct{}{}
+	}
+	out := make([]string, 0)
+	for _, value := range a {
+		if _, ok := inB[value]; !ok {
+			out = append(out, value)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func diffEdges(a, b []Edge) []Edge {
+	inB := make(map[Edge]struct{}, len(b))
+	for _, edge := range b {
+		inB[edge] = struct{}{}
+	}
+	out := make([]Edge, 0)
+	for _, edge := range a {
+		if _, ok := inB[edge]; !ok {
+			out = append(out, edge)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].String() < out[j].String()
+	})
+	return out
+}
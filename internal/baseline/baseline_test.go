@@ -0,0 +1,92 @@
+package baseline
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func baseGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":        {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::Core":       {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"pkg::x::Networking": {ID: "pkg::x::Networking", Label: "Networking", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::x::Networking", Kind: graph.EdgeKindProduct},
+		},
+	}
+}
+
+func TestCompareIdenticalGraphHasNoViolations(t *testing.T) {
+	c := Compare(FromGraph(baseGraph()), FromGraph(baseGraph()))
+	if len(c.Violations) != 0 || len(c.AddedEdges) != 0 || len(c.RemovedNodes) != 0 {
+		t.Fatalf("expected no differences, got %#v", c)
+	}
+}
+
+func TestCompareFlagsGrowth(t *testing.T) {
+	current := baseGraph()
+	current.Nodes["pkg::y::Analytics"] = graph.Node{ID: "pkg::y::Analytics", Label: "Analytics", Kind: graph.NodeKindExternalProduct}
+	current.Edges = append(current.Edges, graph.Edge{FromID: "target::App", ToID: "pkg::y::Analytics", Kind: graph.EdgeKindProduct})
+
+	c := Compare(FromGraph(baseGraph()), FromGraph(current))
+	joined := strings.Join(c.Violations, "\n")
+	for _, part := range []string{
+		"edge count 3 exceeds baseline 2",
+		"external product count 2 exceeds baseline 1",
+		"fan-out of App is 2, exceeds baseline 1",
+	} {
+		if !strings.Contains(joined, part) {
+			t.Fatalf("missing violation %q in %q", part, joined)
+		}
+	}
+	if len(c.AddedNodes) != 1 || c.AddedNodes[0] != "pkg::y::Analytics" {
+		t.Fatalf("unexpected added nodes: %#v", c.AddedNodes)
+	}
+	if len(c.AddedEdges) != 1 || c.AddedEdges[0].To != "pkg::y::Analytics" {
+		t.Fatalf("unexpected added edges: %#v", c.AddedEdges)
+	}
+}
+
+func TestCompareAllowsShrinkingAndNewTargets(t *testing.T) {
+	current := baseGraph()
+	current.Nodes["target::Tool"] = graph.Node{ID: "target::Tool", Label: "Tool", Kind: graph.NodeKindTarget}
+	current.Edges = []graph.Edge{
+		{FromID: "target::Tool", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+	}
+
+	c := Compare(FromGraph(baseGraph()), FromGraph(current))
+	if len(c.Violations) != 0 {
+		t.Fatalf("expected no violations, got %#v", c.Violations)
+	}
+	if len(c.RemovedEdges) != 2 {
+		t.Fatalf("expected removed edges, got %#v", c.RemovedEdges)
+	}
+}
+
+func TestSaveAndLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "deps-baseline.json")
+	if err := Save(path, FromGraph(baseGraph())); err != nil {
+		t.Fatalf("unexpected save error: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	if len(loaded.Nodes) != 3 || len(loaded.Edges) != 2 || loaded.Metrics.Summary.ExternalProducts != 1 {
+		t.Fatalf("unexpected loaded snapshot: %#v", loaded)
+	}
+}
+
+func TestLoadMissingBaseline(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if !apperrors.IsKind(err, apperrors.KindInputNotFound) {
+		t.Fatalf("expected input not found kind, got %v", err)
+	}
+}
@@ -56,6 +56,15 @@ func LoadWorkspace(ctx context.Context, workspacePath, scope string) (Workspace,
 		return Workspace{}, apperrors.New(apperrors.KindBazelParseFailed, "failed to parse bazel label_kind output", err)
 	}
 
+	testOnlyOut, err := runQuery(ctx, workspacePath, binary, fmt.Sprintf(`attr("testonly", "1", %s)`, ruleExpr), "label")
+	if err != nil {
+		return Workspace{}, err
+	}
+	testOnly := make(map[string]bool)
+	for _, label := range parseLabelLines(testOnlyOut) {
+		testOnly[label] = true
+	}
+
 	targets := make([]Target, 0, len(labels))
 	for _, label := range labels {
 		depsExpr := fmt.Sprintf(`kind("rule", deps(%s, 1))`, label)
@@ -83,9 +92,10 @@ func LoadWorkspace(ctx context.Context, workspacePath, scope string) (Workspace,
 		filteredDeps = uniqueSorted(filteredDeps)
 
 		targets = append(targets, Target{
-			Label: label,
-			Kind:  kind,
-			Deps:  filteredDeps,
+			Label:    label,
+			Kind:     kind,
+			Deps:     filteredDeps,
+			TestOnly: testOnly[label],
 		})
 	}
 
@@ -54,6 +54,8 @@ func TestLoadWorkspaceSuccess(t *testing.T) {
 			return []byte("//app:app\n//app:feature\n//app:feature_test\n"), nil, nil
 		case expr == `kind("rule", //app:all)` && output == "label_kind":
 			return []byte("swift_binary rule //app:app\nswift_library rule //app:feature\nswift_test rule //app:feature_test\n"), nil, nil
+		case expr == `attr("testonly", "1", kind("rule", //app:all))` && output == "label":
+			return []byte("//app:feature_test\n"), nil, nil
 		case expr == `kind("rule", deps(//app:app, 1))` && output == "label":
 			return []byte("//app:app\n//app:feature\n@repo//libs:external\n"), nil, nil
 		case expr == `kind("rule", deps(//app:feature, 1))` && output == "label":
@@ -82,6 +84,9 @@ func TestLoadWorkspaceSuccess(t *testing.T) {
 	if got := ws.Targets[0].Deps; len(got) != 2 || got[0] != "//app:feature" || got[1] != "@repo//libs:external" {
 		t.Fatalf("unexpected deps for //app:app: %#v", got)
 	}
+	if ws.Targets[0].TestOnly || ws.Targets[1].TestOnly || !ws.Targets[2].TestOnly {
+		t.Fatalf("expected only //app:feature_test to be testonly: %#v", ws.Targets)
+	}
 }
 
 func TestLoadWorkspaceMissingBinary(t *testing.T) {
@@ -162,6 +167,8 @@ func TestLoadWorkspaceDefaultsScope(t *testing.T) {
 			return []byte("//app:lib\n"), nil, nil
 		case expr == `kind("rule", //...)` && output == "label_kind":
 			return []byte("swift_library rule //app:lib\n"), nil, nil
+		case expr == `attr("testonly", "1", kind("rule", //...))` && output == "label":
+			return nil, nil, nil
 		case expr == `kind("rule", deps(//app:lib, 1))` && output == "label":
 			return []byte("//app:lib\n"), nil, nil
 		default:
@@ -4,6 +4,9 @@ type Target struct {
 	Label string
 	Kind  string
 	Deps  []string
+	// TestOnly is set for rules with testonly = True, which covers test rules and
+	// test-support libraries.
+	TestOnly bool
 }
 
 type Workspace struct {
@@ -8,8 +8,47 @@ import (
 	"swift-deps-diagram/internal/graph"
 )
 
+// isTestRuleKind reports whether kind is a test rule: *_test rules, test_suite, and the
+// test bundle rules that macros such as ios_unit_test generate.
 func isTestRuleKind(kind string) bool {
-	return strings.HasSuffix(kind, "_test")
+	return strings.HasSuffix(kind, "_test") || kind == "test_suite" || strings.HasSuffix(kind, "_test_bundle")
+}
+
+// isTestTarget reports whether target is test code: a test rule or a testonly rule such
+// as a test-support library.
+func isTestTarget(target bazel.Target) bool {
+	return target.TestOnly || isTestRuleKind(target.Kind)
+}
+
+// ruleTargetType maps a Bazel rule kind to a graph.TargetType.
+func ruleTargetType(kind string) graph.TargetType {
+	switch {
+	case kind == "test_suite":
+		return graph.TargetTypeAggregate
+	case strings.HasSuffix(kind, "_ui_test") || strings.HasSuffix(kind, "_ui_test_bundle"):
+		return graph.TargetTypeUITest
+	case strings.HasSuffix(kind, "_test") || strings.HasSuffix(kind, "_test_bundle"):
+		return graph.TargetTypeTest
+	case strings.HasSuffix(kind, "_application") && !strings.HasSuffix(kind, "_command_line_application"),
+		strings.HasSuffix(kind, "_app_clip"):
+		return graph.TargetTypeApp
+	case strings.HasSuffix(kind, "_extension"):
+		return graph.TargetTypeAppExtension
+	case strings.HasSuffix(kind, "_import"):
+		return graph.TargetTypeBinary
+	case strings.HasSuffix(kind, "_framework") || strings.HasSuffix(kind, "_xcframework"):
+		return graph.TargetTypeFramework
+	case kind == "swift_compiler_plugin":
+		return graph.TargetTypeMacro
+	case kind == "swift_c_module":
+		return graph.TargetTypeSystemLibrary
+	case strings.HasSuffix(kind, "_binary") || strings.HasSuffix(kind, "_command_line_application"):
+		return graph.TargetTypeExecutable
+	case strings.HasSuffix(kind, "_library"):
+		return graph.TargetTypeLibrary
+	default:
+		return ""
+	}
 }
 
 func targetNodeID(label string) string {
@@ -20,6 +59,23 @@ func externalNodeID(label string) string {
 	return "external::" + label
 }
 
+// packagePath returns the package directory of a local label, e.g. "app/feature" for "//app/feature:lib".
+func packagePath(label string) string {
+	pkg := strings.TrimPrefix(label, "//")
+	if i := strings.Index(pkg, ":"); i >= 0 {
+		pkg = pkg[:i]
+	}
+	return pkg
+}
+
+// externalRepo returns the repository part of an external label, e.g. "@repo" for "@repo//pkg:name".
+func externalRepo(label string) string {
+	if i := strings.Index(label, "//"); i > 0 {
+		return label[:i]
+	}
+	return label
+}
+
 func Build(workspace bazel.Workspace, includeTests bool) (graph.Graph, error) {
 	nodes := make(map[string]graph.Node)
 	edges := make([]graph.Edge, 0)
@@ -30,19 +86,19 @@ func Build(workspace bazel.Workspace, includeTests bool) (graph.Graph, error) {
 		return targets[i].Label < targets[j].Label
 	})
 
-	kindByLabel := make(map[string]string, len(targets))
+	testByLabel := make(map[string]bool, len(targets))
 	included := make(map[string]struct{}, len(targets))
 	for _, target := range targets {
-		kindByLabel[target.Label] = target.Kind
+		testByLabel[target.Label] = isTestTarget(target)
 		if target.Label == "" {
 			continue
 		}
-		if !includeTests && isTestRuleKind(target.Kind) {
+		if !includeTests && isTestTarget(target) {
 			continue
 		}
 		included[target.Label] = struct{}{}
 		id := targetNodeID(target.Label)
-		nodes[id] = graph.Node{ID: id, Label: target.Label, Kind: graph.NodeKindTarget}
+		nodes[id] = graph.Node{ID: id, Label: target.Label, Kind: graph.NodeKindTarget, Path: packagePath(target.Label), Test: isTestTarget(target), NativeType: target.Kind, Type: ruleTargetType(target.Kind)}
 	}
 
 	for _, target := range targets {
@@ -58,7 +114,7 @@ func Build(workspace bazel.Workspace, includeTests bool) (graph.Graph, error) {
 			case strings.HasPrefix(dep, "@"):
 				toID := externalNodeID(dep)
 				if _, ok := nodes[toID]; !ok {
-					nodes[toID] = graph.Node{ID: toID, Label: dep, Kind: graph.NodeKindExternalProduct}
+					nodes[toID] = graph.Node{ID: toID, Label: dep, Kind: graph.NodeKindExternalProduct, Package: externalRepo(dep)}
 				}
 				edge := graph.Edge{FromID: fromID, ToID: toID, Kind: graph.EdgeKindProduct}
 				key := graph.EdgeKey(edge)
@@ -68,12 +124,12 @@ func Build(workspace bazel.Workspace, includeTests bool) (graph.Graph, error) {
 				edgeDedup[key] = struct{}{}
 				edges = append(edges, edge)
 			case strings.HasPrefix(dep, "//"):
-				if kind, ok := kindByLabel[dep]; ok && !includeTests && isTestRuleKind(kind) {
+				if !includeTests && testByLabel[dep] {
 					continue
 				}
 				toID := targetNodeID(dep)
 				if _, ok := nodes[toID]; !ok {
-					nodes[toID] = graph.Node{ID: toID, Label: dep, Kind: graph.NodeKindTarget}
+					nodes[toID] = graph.Node{ID: toID, Label: dep, Kind: graph.NodeKindTarget, Path: packagePath(dep)}
 				}
 				edge := graph.Edge{FromID: fromID, ToID: toID, Kind: graph.EdgeKindTarget}
 				key := graph.EdgeKey(edge)
@@ -48,11 +48,15 @@ func TestBuildMapsLocalAndExternalDeps(t *testing.T) {
 		t.Fatalf("unexpected build error: %v", err)
 	}
 
-	if _, ok := g.Nodes["target:://app:lib"]; !ok {
+	if local, ok := g.Nodes["target:://app:lib"]; !ok {
 		t.Fatal("missing local dep node")
+	} else if local.Path != "app" {
+		t.Fatalf("expected package path app, got %q", local.Path)
 	}
-	if _, ok := g.Nodes["external::@repo//pkg:network"]; !ok {
+	if external, ok := g.Nodes["external::@repo//pkg:network"]; !ok {
 		t.Fatal("missing external dep node")
+	} else if external.Package != "@repo" {
+		t.Fatalf("expected external repository @repo, got %q", external.Package)
 	}
 
 	localFound := false
@@ -99,8 +103,47 @@ func TestBuildRespectsIncludeTests(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected build error: %v", err)
 	}
-	if _, ok := withTests.Nodes["target:://app:bin_test"]; !ok {
+	if node, ok := withTests.Nodes["target:://app:bin_test"]; !ok {
 		t.Fatal("expected test node when includeTests=true")
+	} else if !node.Test || node.NativeType == "" {
+		t.Fatalf("expected test node marked as test with its rule kind, got %#v", node)
+	}
+}
+
+func TestBuildHidesTestSupportAndGeneratedTestRules(t *testing.T) {
+	workspace := bazel.Workspace{
+		Targets: []bazel.Target{
+			{Label: "//app:lib", Kind: "swift_library"},
+			{Label: "//app:mocks", Kind: "swift_library", Deps: []string{"//app:lib"}, TestOnly: true},
+			{Label: "//app:tests", Kind: "ios_unit_test", Deps: []string{"//app:tests.__internal__.__test_bundle"}, TestOnly: true},
+			{Label: "//app:tests.__internal__.__test_bundle", Kind: "_ios_internal_unit_test_bundle", Deps: []string{"//app:mocks"}},
+			{Label: "//app:all_tests", Kind: "test_suite", Deps: []string{"//app:tests"}},
+		},
+	}
+
+	withoutTests, err := Build(workspace, false)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if len(withoutTests.Nodes) != 1 || len(withoutTests.Edges) != 0 {
+		t.Fatalf("expected only //app:lib without tests, got %#v", withoutTests)
+	}
+
+	withTests, err := Build(workspace, true)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	for label, want := range map[string]graph.TargetType{
+		"//app:mocks":                            graph.TargetTypeLibrary,
+		"//app:tests.__internal__.__test_bundle": graph.TargetTypeTest,
+		"//app:all_tests":                        graph.TargetTypeAggregate,
+	} {
+		if node := withTests.Nodes[targetNodeID(label)]; !node.Test || node.Type != want {
+			t.Fatalf("expected %s marked as test of type %s, got %#v", label, want, node)
+		}
+	}
+	if withTests.Nodes[targetNodeID("//app:lib")].Test {
+		t.Fatal("did not expect //app:lib marked as test")
 	}
 }
 
@@ -131,3 +174,39 @@ func TestBuildDeterministicAndDeduped(t *testing.T) {
 		t.Fatalf("unexpected second edge order: %#v", g.Edges)
 	}
 }
+
+func TestRuleTargetTypeNormalizesRuleKinds(t *testing.T) {
+	cases := map[string]graph.TargetType{
+		"swift_library":                    graph.TargetTypeLibrary,
+		"objc_library":                     graph.TargetTypeLibrary,
+		"swift_binary":                     graph.TargetTypeExecutable,
+		"macos_command_line_application":   graph.TargetTypeExecutable,
+		"swift_test":                       graph.TargetTypeTest,
+		"ios_unit_test":                    graph.TargetTypeTest,
+		"ios_ui_test":                      graph.TargetTypeUITest,
+		"_ios_internal_ui_test_bundle":     graph.TargetTypeUITest,
+		"ios_application":                  graph.TargetTypeApp,
+		"ios_app_clip":                     graph.TargetTypeApp,
+		"ios_extension":                    graph.TargetTypeAppExtension,
+		"ios_framework":                    graph.TargetTypeFramework,
+		"apple_static_xcframework":         graph.TargetTypeFramework,
+		"apple_dynamic_xcframework_import": graph.TargetTypeBinary,
+		"swift_compiler_plugin":            graph.TargetTypeMacro,
+		"swift_c_module":                   graph.TargetTypeSystemLibrary,
+		"test_suite":                       graph.TargetTypeAggregate,
+		"genrule":                          "",
+	}
+	for kind, want := range cases {
+		if got := ruleTargetType(kind); got != want {
+			t.Fatalf("ruleTargetType(%q) = %q, want %q", kind, got, want)
+		}
+	}
+
+	g, err := Build(bazel.Workspace{Targets: []bazel.Target{{Label: "//app:App", Kind: "ios_application"}}}, false)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if node := g.Nodes["target:://app:App"]; node.Type != graph.TargetTypeApp || node.NativeType != "ios_application" {
+		t.Fatalf("unexpected node types %#v", node)
+	}
+}
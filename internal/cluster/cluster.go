@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// Usage lists the supported --cluster-by values for help and error text.
+const Usage = "package|directory|project|bazel-package|regex:<expr>"
+
+// Rule assigns nodes to named groups.
+type Rule struct {
+	key     string
+	pattern *regexp.Regexp
+}
+
+// Parse validates a --cluster-by specification. An empty spec yields a rule that
+// assigns no groups.
+func Parse(spec string) (Rule, error) {
+	switch spec {
+	case "", "package", "directory", "project", "bazel-package":
+		return Rule{key: spec}, nil
+	}
+	if expr, ok := strings.CutPrefix(spec, "regex:"); ok {
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return Rule{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("invalid --cluster-by regex %q", expr), err)
+		}
+		return Rule{key: "regex", pattern: pattern}, nil
+	}
+	return Rule{}, apperrors.New(apperrors.KindInvalidArgs, "--cluster-by must be one of: "+Usage, nil)
+}
+
+// Assign maps node IDs to group names. Nodes without a group are omitted.
+func (r Rule) Assign(g graph.Graph) map[string]string {
+	groups := make(map[string]string)
+	if r.key == "" {
+		return groups
+	}
+	for id, node := range g.Nodes {
+		if group := r.group(node); group != "" {
+			groups[id] = group
+		}
+	}
+	return groups
+}
+
+func (r Rule) group(node graph.Node) string {
+	switch r.key {
+	case "package":
+		return node.Package
+	case "directory":
+		return node.Path
+	case "project":
+		return node.Project
+	case "bazel-package":
+		return bazelPackage(node.Label)
+	case "regex":
+		match := r.pattern.FindStringSubmatch(node.Label)
+		if match == nil {
+			return ""
+		}
+		if len(match) > 1 {
+			return match[1]
+		}
+		return match[0]
+	default:
+		return ""
+	}
+}
+
+// bazelPackage returns the package part of a Bazel label, e.g. "//app/feature" for
+// "//app/feature:lib" or "@repo//pkg" for "@repo//pkg:name".
+func bazelPackage(label string) string {
+	if !strings.HasPrefix(label, "//") && !strings.HasPrefix(label, "@") {
+		return ""
+	}
+	if i := strings.LastIndex(label, ":"); i > strings.Index(label, "//") {
+		return label[:i]
+	}
+	return label
+}
@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func sampleGraph() graph.Graph {
+	return graph.Graph{Nodes: map[string]graph.Node{
+		"target::App":               {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget, Package: "Sample", Path: "Sources/App", Project: "App"},
+		"target:://app/feature:lib": {ID: "target:://app/feature:lib", Label: "//app/feature:lib", Kind: graph.NodeKindTarget},
+		"pkg::x::FeatureKit":        {ID: "pkg::x::FeatureKit", Label: "FeatureKit", Kind: graph.NodeKindExternalProduct, Package: "x"},
+	}}
+}
+
+func TestParseRejectsUnknownRules(t *testing.T) {
+	for _, spec := range []string{"module", "regex:("} {
+		if _, err := Parse(spec); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args for %q, got %v", spec, err)
+		}
+	}
+}
+
+func TestAssignByNodeFields(t *testing.T) {
+	cases := map[string]map[string]string{
+		"":              {},
+		"package":       {"target::App": "Sample", "pkg::x::FeatureKit": "x"},
+		"directory":     {"target::App": "Sources/App"},
+		"project":       {"target::App": "App"},
+		"bazel-package": {"target:://app/feature:lib": "//app/feature"},
+	}
+	for spec, want := range cases {
+		rule, err := Parse(spec)
+		if err != nil {
+			t.Fatalf("unexpected parse error for %q: %v", spec, err)
+		}
+		got := rule.Assign(sampleGraph())
+		if len(got) != len(want) {
+			t.Fatalf("%q: expected %#v, got %#v", spec, want, got)
+		}
+		for id, group := range want {
+			if got[id] != group {
+				t.Fatalf("%q: expected %s in %q, got %q", spec, id, group, got[id])
+			}
+		}
+	}
+}
+
+func TestAssignByRegexUsesFirstCaptureGroup(t *testing.T) {
+	rule, err := Parse("regex:^(Feature|//app/feature)")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	got := rule.Assign(sampleGraph())
+	if len(got) != 2 || got["pkg::x::FeatureKit"] != "Feature" || got["target:://app/feature:lib"] != "//app/feature" {
+		t.Fatalf("unexpected regex groups: %#v", got)
+	}
+}
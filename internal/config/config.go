@@ -0,0 +1,470 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+// FileName is the project configuration file looked up from the input path upward.
+const FileName = ".swift-deps-diagram.yml"
+
+// Setting is one key of the configuration file. Keys are CLI flag names without the
+// leading dashes.
+type Setting struct {
+	Key string
+	// Values holds the scalar value, or every item when List is set.
+	Values []string
+	List   bool
+	Line   int
+}
+
+// Settings are the keys of one configuration level in file order.
+type Settings []Setting
+
+// File is a parsed configuration file: top-level defaults plus named views, each a
+// preset of settings applied over the defaults.
+type File struct {
+	Path     string
+	Settings Settings
+	Views    map[string]Settings
+}
+
+// Find returns the first FileName in start or one of its parent directories.
+func Find(start string) (string, bool) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, FileName)
+		if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Load reads and parses the configuration file at path.
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading config %s", path), err)
+	}
+	return Parse(path, data)
+}
+
+// Parse parses configuration data; path is only used in error messages.
+func Parse(path string, data []byte) (File, error) {
+	p, err := newParser(path, string(data))
+	if err != nil {
+		return File{}, err
+	}
+	root, err := p.parseDocument()
+	if err != nil {
+		return File{}, err
+	}
+
+	file := File{Path: path, Views: map[string]Settings{}}
+	for _, entry := range root.entries {
+		switch entry.key {
+		case "view":
+			return File{}, p.errorf(entry.value.line, "view cannot be set in the configuration file; pass --view instead")
+		case "views":
+			if entry.value.kind == nodeScalar && entry.value.scalar == "" {
+				continue
+			}
+			if entry.value.kind != nodeMap {
+				return File{}, p.errorf(entry.value.line, "views must map view names to settings")
+			}
+			for _, view := range entry.value.entries {
+				settings, err := p.viewSettings(view)
+				if err != nil {
+					return File{}, err
+				}
+				file.Views[view.key] = settings
+			}
+		default:
+			setting, err := p.setting(entry, entry.key)
+			if err != nil {
+				return File{}, err
+			}
+			file.Settings = append(file.Settings, setting)
+		}
+	}
+	return file, nil
+}
+
+func (p *parser) viewSettings(view entry) (Settings, error) {
+	if view.value.kind == nodeScalar && view.value.scalar == "" {
+		return Settings{}, nil
+	}
+	if view.value.kind != nodeMap {
+		return nil, p.errorf(view.value.line, "view %q must map keys to values", view.key)
+	}
+	settings := Settings{}
+	for _, entry := range view.value.entries {
+		if entry.key == "view" || entry.key == "views" {
+			return nil, p.errorf(entry.value.line, "view %q cannot set %s", view.key, entry.key)
+		}
+		setting, err := p.setting(entry, "views."+view.key+"."+entry.key)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+func (p *parser) setting(e entry, name string) (Setting, error) {
+	switch e.value.kind {
+	case nodeScalar:
+		return Setting{Key: e.key, Values: []string{e.value.scalar}, Line: e.value.line}, nil
+	case nodeList:
+		return Setting{Key: e.key, Values: e.value.items, List: true, Line: e.value.line}, nil
+	default:
+		return Setting{}, p.errorf(e.value.line, "%s must be a value or a list of values", name)
+	}
+}
+
+// ViewNames returns the view names in sorted order.
+func (f File) ViewNames() []string {
+	names := make([]string, 0, len(f.Views))
+	for name := range f.Views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the top-level settings with the named view applied over them. An
+// empty name selects the top-level settings alone.
+func (f File) Select(view string) (Settings, error) {
+	if view == "" {
+		return f.Settings, nil
+	}
+	overrides, ok := f.Views[view]
+	if !ok {
+		available := "none defined"
+		if names := f.ViewNames(); len(names) > 0 {
+			available = "available: " + strings.Join(names, ", ")
+		}
+		return nil, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("unknown view %q in %s (%s)", view, f.Path, available), nil)
+	}
+	return f.Settings.Merge(overrides), nil
+}
+
+// Merge returns s with every key of overrides replacing the matching key of s. New keys
+// are appended in overrides order.
+func (s Settings) Merge(overrides Settings) Settings {
+	merged := append(Settings{}, s...)
+	for _, override := range overrides {
+		replaced := false
+		for i := range merged {
+			if merged[i].Key == override.Key {
+				merged[i] = override
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+	return merged
+}
+
+// The parser accepts the YAML subset needed for flat settings: block mappings, block
+// sequences ("- item"), flow sequences ("[a, b]"), plain, single-quoted and
+// double-quoted scalars, and comments.
+
+type nodeKind int
+
+const (
+	nodeScalar nodeKind = iota
+	nodeList
+	nodeMap
+)
+
+type node struct {
+	kind    nodeKind
+	line    int
+	scalar  string
+	items   []string
+	entries []entry
+}
+
+type entry struct {
+	key   string
+	value node
+}
+
+type line struct {
+	number int
+	indent int
+	text   string
+}
+
+type parser struct {
+	path  string
+	lines []line
+	pos   int
+}
+
+func newParser(path, data string) (*parser, error) {
+	p := &parser{path: path}
+	for i, raw := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		number := i + 1
+		text, err := stripComment(raw)
+		if err != nil {
+			return nil, p.errorf(number, "%v", err)
+		}
+		text = strings.TrimRight(text, " \t")
+		content := strings.TrimLeft(text, " ")
+		if content == "" || (len(p.lines) == 0 && content == "---") {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, p.errorf(number, "tabs cannot be used for indentation")
+		}
+		p.lines = append(p.lines, line{number: number, indent: len(text) - len(content), text: content})
+	}
+	return p, nil
+}
+
+// stripComment removes a # comment that starts the line or follows whitespace outside
+// quoted scalars. Quotes only open a scalar at its start, so "it's" stays plain.
+func stripComment(text string) (string, error) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[,", text[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i], nil
+		}
+	}
+	if quote != 0 {
+		return "", fmt.Errorf("unterminated quoted string")
+	}
+	return text, nil
+}
+
+func (p *parser) errorf(lineNumber int, format string, args ...any) error {
+	return apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s:%d: %s", p.path, lineNumber, fmt.Sprintf(format, args...)), nil)
+}
+
+func (p *parser) parseDocument() (node, error) {
+	if len(p.lines) == 0 {
+		return node{kind: nodeMap}, nil
+	}
+	first := p.lines[0]
+	if first.indent != 0 {
+		return node{}, p.errorf(first.number, "unexpected indentation")
+	}
+	if isListItem(first.text) {
+		return node{}, p.errorf(first.number, "the configuration must be a mapping of keys to values")
+	}
+	root, err := p.parseMap(0)
+	if err != nil {
+		return node{}, err
+	}
+	if p.pos < len(p.lines) {
+		return node{}, p.errorf(p.lines[p.pos].number, "unexpected indentation")
+	}
+	return root, nil
+}
+
+func isListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *parser) parseMap(indent int) (node, error) {
+	result := node{kind: nodeMap, line: p.lines[p.pos].number}
+	seen := map[string]bool{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		current := p.lines[p.pos]
+		if isListItem(current.text) {
+			return node{}, p.errorf(current.number, "unexpected list item")
+		}
+		key, rest, ok := splitKey(current.text)
+		if !ok {
+			return node{}, p.errorf(current.number, "expected \"key: value\"")
+		}
+		if seen[key] {
+			return node{}, p.errorf(current.number, "duplicate key %q", key)
+		}
+		seen[key] = true
+		p.pos++
+
+		var value node
+		var err error
+		switch {
+		case rest != "":
+			value, err = p.parseInline(current.number, rest)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			value, err = p.parseBlock(p.lines[p.pos].indent)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isListItem(p.lines[p.pos].text):
+			value, err = p.parseList(indent)
+		default:
+			value = node{kind: nodeScalar, line: current.number}
+		}
+		if err != nil {
+			return node{}, err
+		}
+		result.entries = append(result.entries, entry{key: key, value: value})
+	}
+	return result, nil
+}
+
+func (p *parser) parseBlock(indent int) (node, error) {
+	if isListItem(p.lines[p.pos].text) {
+		return p.parseList(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *parser) parseList(indent int) (node, error) {
+	result := node{kind: nodeList, line: p.lines[p.pos].number, items: []string{}}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isListItem(p.lines[p.pos].text) {
+		current := p.lines[p.pos]
+		item := strings.TrimSpace(strings.TrimPrefix(current.text, "-"))
+		if item == "" || isListItem(item) || strings.HasPrefix(item, "[") {
+			return node{}, p.errorf(current.number, "list items must be plain values")
+		}
+		if _, _, isKey := splitKey(item); isKey {
+			return node{}, p.errorf(current.number, "list items must be plain values")
+		}
+		value, err := unquote(item)
+		if err != nil {
+			return node{}, p.errorf(current.number, "%v", err)
+		}
+		result.items = append(result.items, value)
+		p.pos++
+		if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			return node{}, p.errorf(p.lines[p.pos].number, "list items must be plain values")
+		}
+	}
+	return result, nil
+}
+
+func (p *parser) parseInline(lineNumber int, text string) (node, error) {
+	if strings.HasPrefix(text, "[") {
+		if !strings.HasSuffix(text, "]") {
+			return node{}, p.errorf(lineNumber, "unterminated flow list")
+		}
+		items, err := splitFlow(strings.TrimSpace(text[1 : len(text)-1]))
+		if err != nil {
+			return node{}, p.errorf(lineNumber, "%v", err)
+		}
+		return node{kind: nodeList, line: lineNumber, items: items}, nil
+	}
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "&") || strings.HasPrefix(text, "*") || strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return node{}, p.errorf(lineNumber, "unsupported YAML value %q", text)
+	}
+	value, err := unquote(text)
+	if err != nil {
+		return node{}, p.errorf(lineNumber, "%v", err)
+	}
+	return node{kind: nodeScalar, line: lineNumber, scalar: value}, nil
+}
+
+// splitKey splits "key: rest" or "key:"; keys are plain words.
+func splitKey(text string) (string, string, bool) {
+	colon := strings.Index(text, ":")
+	for colon >= 0 && colon+1 < len(text) && text[colon+1] != ' ' {
+		next := strings.Index(text[colon+1:], ":")
+		if next < 0 {
+			return "", "", false
+		}
+		colon += 1 + next
+	}
+	if colon <= 0 {
+		return "", "", false
+	}
+	key := strings.TrimSpace(text[:colon])
+	if key == "" || strings.ContainsAny(key, "\"' [{") {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(text[colon+1:]), true
+}
+
+func splitFlow(text string) ([]string, error) {
+	items := []string{}
+	if text == "" {
+		return items, nil
+	}
+	var quote byte
+	start := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) {
+			c := text[i]
+			switch {
+			case quote == '"' && c == '\\':
+				i++
+				continue
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+				continue
+			case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t,", text[i-1]) >= 0):
+				quote = c
+				continue
+			case c == '[' || c == ']' || c == '{' || c == '}':
+				return nil, fmt.Errorf("nested flow collections are not supported")
+			case c != ',':
+				continue
+			}
+		}
+		item := strings.TrimSpace(text[start:i])
+		if item == "" {
+			return nil, fmt.Errorf("empty flow list item")
+		}
+		value, err := unquote(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		start = i + 1
+	}
+	return items, nil
+}
+
+func unquote(text string) (string, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted string %s", text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return "", fmt.Errorf("invalid single-quoted string %s", text)
+		}
+		inner := text[1 : len(text)-1]
+		if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+			return "", fmt.Errorf("invalid single-quoted string %s", text)
+		}
+		return strings.ReplaceAll(inner, "''", "'"), nil
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+const sample = `# project defaults
+---
+mode: bazel
+bazel-targets: "//ios/..."   # quoted because of the slashes
+include-tests: true
+link-base: https://example.com/repo/tree/main#readme
+title: it's fine
+emit:
+  - mermaid=docs/deps.mmd
+  - 'svg=docs/deps.svg'
+views:
+  overview:
+    hide-external: true
+    collapse-packages: true
+  tests:
+    emit: [terminal, "json=deps.json"]
+  empty:
+`
+
+func TestParseReadsSettingsAndViews(t *testing.T) {
+	file, err := Parse("cfg.yml", []byte(sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Settings{
+		{Key: "mode", Values: []string{"bazel"}, Line: 3},
+		{Key: "bazel-targets", Values: []string{"//ios/..."}, Line: 4},
+		{Key: "include-tests", Values: []string{"true"}, Line: 5},
+		{Key: "link-base", Values: []string{"https://example.com/repo/tree/main#readme"}, Line: 6},
+		{Key: "title", Values: []string{"it's fine"}, Line: 7},
+		{Key: "emit", Values: []string{"mermaid=docs/deps.mmd", "svg=docs/deps.svg"}, List: true, Line: 9},
+	}
+	if !reflect.DeepEqual(file.Settings, want) {
+		t.Fatalf("unexpected settings:\n%#v\nwant\n%#v", file.Settings, want)
+	}
+	if names := file.ViewNames(); !reflect.DeepEqual(names, []string{"empty", "overview", "tests"}) {
+		t.Fatalf("unexpected views %v", names)
+	}
+	if got := file.Views["tests"]; len(got) != 1 || !reflect.DeepEqual(got[0].Values, []string{"terminal", "json=deps.json"}) || !got[0].List {
+		t.Fatalf("unexpected flow list %#v", got)
+	}
+}
+
+func TestSelectAppliesViewOverDefaults(t *testing.T) {
+	file, err := Parse("cfg.yml", []byte(sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	settings, err := file.Select("tests")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := []string{}
+	for _, setting := range settings {
+		keys = append(keys, setting.Key+"="+strings.Join(setting.Values, ","))
+	}
+	want := "mode=bazel bazel-targets=//ios/... include-tests=true link-base=https://example.com/repo/tree/main#readme title=it's fine emit=terminal,json=deps.json"
+	if strings.Join(keys, " ") != want {
+		t.Fatalf("unexpected merged settings %q", strings.Join(keys, " "))
+	}
+
+	settings, _ = file.Select("overview")
+	if len(settings) != 8 || settings[7].Key != "collapse-packages" {
+		t.Fatalf("expected view keys appended, got %#v", settings)
+	}
+
+	_, err = file.Select("missing")
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) || !strings.Contains(err.Error(), "available: empty, overview, tests") {
+		t.Fatalf("expected unknown view error, got %v", err)
+	}
+}
+
+func TestParseRejectsUnsupportedYAML(t *testing.T) {
+	cases := map[string]string{
+		"nested map":        "theme:\n  name: dark\n",
+		"tab indent":        "views:\n\toverview:\n",
+		"duplicate key":     "mode: spm\nmode: xcode\n",
+		"unterminated":      "mode: \"spm\n",
+		"anchor":            "mode: &m spm\n",
+		"bad indentation":   "mode: spm\n  format: dot\n",
+		"mapping list item": "emit:\n  - format: dot\n",
+		"top-level list":    "- mode\n",
+		"view key":          "view: overview\n",
+		"view scalar":       "views:\n  overview: dark\n",
+		"missing colon":     "mode spm\n",
+	}
+	for name, data := range cases {
+		_, err := Parse("cfg.yml", []byte(data))
+		if !apperrors.IsKind(err, apperrors.KindInvalidArgs) || !strings.HasPrefix(err.Error(), "cfg.yml:") {
+			t.Fatalf("%s: expected invalid args with location, got %v", name, err)
+		}
+	}
+}
+
+func TestFindWalksUpFromStart(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "ios", "App")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if _, ok := Find(nested); ok {
+		t.Fatal("expected no config before one is written")
+	}
+
+	configPath := filepath.Join(root, FileName)
+	if err := os.WriteFile(configPath, []byte("mode: spm\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, ok := Find(nested)
+	if !ok || got != configPath {
+		t.Fatalf("expected %s, got %q (%v)", configPath, got, ok)
+	}
+	file, err := Load(got)
+	if err != nil || len(file.Settings) != 1 || file.Settings[0].Values[0] != "spm" {
+		t.Fatalf("unexpected load result %#v (%v)", file, err)
+	}
+}
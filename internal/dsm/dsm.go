@@ -0,0 +1,208 @@
+package dsm
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// Matrix is a dependency structure matrix. Row i depends on column j when Cells[i][j]
+// is non-zero. Nodes are ordered by level, so acyclic dependencies always fall below the
+// diagonal and marks above it belong to cycles.
+type Matrix struct {
+	IDs    []string
+	Labels []string
+	// Levels holds the topological level of each node: the number of edges on the longest
+	// dependency chain below it, with cycles collapsed.
+	Levels []int
+	// Cells counts the dependencies from row to column, using edge weights.
+	Cells [][]int
+}
+
+// Build orders the nodes of g by level, keeping the members of each cycle together,
+// and fills the matrix with the direct dependencies between them.
+func Build(g graph.Graph) Matrix {
+	depths := graph.Depths(g)
+	componentOf := graph.Condense(g).ComponentOf
+	ids := graph.SortedNodeIDs(g)
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, b := ids[i], ids[j]
+		if depths[a] != depths[b] {
+			return depths[a] < depths[b]
+		}
+		if componentOf[a] != componentOf[b] {
+			return componentOf[a] < componentOf[b]
+		}
+		if g.Nodes[a].Label != g.Nodes[b].Label {
+			return g.Nodes[a].Label < g.Nodes[b].Label
+		}
+		return a < b
+	})
+
+	index := make(map[string]int, len(ids))
+	m := Matrix{
+		IDs:    ids,
+		Labels: make([]string, len(ids)),
+		Levels: make([]int, len(ids)),
+		Cells:  make([][]int, len(ids)),
+	}
+	for i, id := range ids {
+		index[id] = i
+		m.Labels[i] = strings.ReplaceAll(g.Nodes[id].Label, "\n", " ")
+		m.Levels[i] = depths[id]
+		m.Cells[i] = make([]int, len(ids))
+	}
+	for _, edge := range g.Edges {
+		from, fromOK := index[edge.FromID]
+		to, toOK := index[edge.ToID]
+		if !fromOK || !toOK {
+			continue
+		}
+		weight := edge.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		m.Cells[from][to] += weight
+	}
+	return m
+}
+
+// AboveDiagonal counts the marked cells above the diagonal, i.e. dependencies that are
+// part of a cycle.
+func (m Matrix) AboveDiagonal() int {
+	count := 0
+	for i, row := range m.Cells {
+		for j := i + 1; j < len(row); j++ {
+			if row[j] > 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func textCell(m Matrix, i, j int) string {
+	switch {
+	case i == j:
+		return "-"
+	case m.Cells[i][j] == 0:
+		return "."
+	case j > i:
+		return "!"
+	default:
+		return "x"
+	}
+}
+
+// Text renders the matrix as numbered rows and columns. Dependencies are marked "x"
+// below the diagonal and "!" above it.
+func Text(m Matrix) (string, error) {
+	if len(m.IDs) == 0 {
+		return "(empty)", nil
+	}
+
+	numberWidth := len(strconv.Itoa(len(m.IDs)))
+	labelWidth := len("NODE")
+	for _, label := range m.Labels {
+		if len(label) > labelWidth {
+			labelWidth = len(label)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%*s  %-*s  LEVEL ", numberWidth, "#", labelWidth, "NODE"))
+	for j := range m.IDs {
+		b.WriteString(fmt.Sprintf(" %*d", numberWidth, j+1))
+	}
+	b.WriteString("\n")
+	for i, label := range m.Labels {
+		b.WriteString(fmt.Sprintf("%*d  %-*s  %5d ", numberWidth, i+1, labelWidth, label, m.Levels[i]))
+		for j := range m.IDs {
+			b.WriteString(fmt.Sprintf(" %*s", numberWidth, textCell(m, i, j)))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(fmt.Sprintf("\nrows depend on columns; x below the diagonal, ! above the diagonal (cycles): %d", m.AboveDiagonal()))
+	return b.String(), nil
+}
+
+// CSV renders the matrix with node labels as the header row and first column. Cells
+// hold the dependency count and are empty when there is none.
+func CSV(m Matrix) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(append([]string{""}, m.Labels...)); err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to write DSM CSV", err)
+	}
+	for i, label := range m.Labels {
+		record := make([]string, 0, len(m.IDs)+1)
+		record = append(record, label)
+		for j := range m.IDs {
+			if m.Cells[i][j] == 0 {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, strconv.Itoa(m.Cells[i][j]))
+		}
+		if err := w.Write(record); err != nil {
+			return "", apperrors.New(apperrors.KindRuntime, "failed to write DSM CSV", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to write DSM CSV", err)
+	}
+	return b.String(), nil
+}
+
+const htmlStyle = `body { font: 12px -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 0; }
+th { font-weight: normal; background: #f6f8fa; padding: 2px 6px; white-space: nowrap; }
+thead th.col { writing-mode: vertical-rl; transform: rotate(180deg); text-align: left; padding: 6px 2px; }
+td { width: 18px; height: 18px; min-width: 18px; text-align: center; font-size: 10px; }
+td.self { background: #8c959f; }
+td.dep { background: #4c78a8; color: #fff; }
+td.cycle { background: #d62728; color: #fff; }
+.legend span { display: inline-block; width: 12px; height: 12px; margin: 0 4px 0 12px; vertical-align: middle; }`
+
+// HTML renders the matrix as a standalone page with colored cells: blue for
+// dependencies below the diagonal and red for cycle dependencies above it.
+func HTML(m Matrix) (string, error) {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>Dependency structure matrix</title>\n")
+	b.WriteString("<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n")
+	b.WriteString(fmt.Sprintf("<p class=\"legend\">Rows depend on columns.<span style=\"background:#4c78a8\"></span>dependency<span style=\"background:#d62728\"></span>cycle (above the diagonal): %d</p>\n", m.AboveDiagonal()))
+	b.WriteString("<table>\n<thead>\n<tr><th>#</th><th>Node</th><th>Level</th>")
+	for j, label := range m.Labels {
+		b.WriteString(fmt.Sprintf("<th class=\"col\" title=\"%s\">%d</th>", html.EscapeString(label), j+1))
+	}
+	b.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for i, label := range m.Labels {
+		b.WriteString(fmt.Sprintf("<tr><th>%d</th><th title=\"%s\">%s</th><th>%d</th>", i+1, html.EscapeString(m.IDs[i]), html.EscapeString(label), m.Levels[i]))
+		for j := range m.IDs {
+			switch {
+			case i == j:
+				b.WriteString("<td class=\"self\"></td>")
+			case m.Cells[i][j] == 0:
+				b.WriteString("<td></td>")
+			default:
+				class := "dep"
+				if j > i {
+					class = "cycle"
+				}
+				title := html.EscapeString(fmt.Sprintf("%s → %s", label, m.Labels[j]))
+				b.WriteString(fmt.Sprintf("<td class=\"%s\" title=\"%s\">%d</td>", class, title, m.Cells[i][j]))
+			}
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n</body>\n</html>")
+	return b.String(), nil
+}
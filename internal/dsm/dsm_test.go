@@ -0,0 +1,106 @@
+package dsm
+
+import (
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func sampleGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":        {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::Feature":    {ID: "target::Feature", Label: "Feature", Kind: graph.NodeKindTarget},
+			"target::Core":       {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"target::Util":       {ID: "target::Util", Label: "Util", Kind: graph.NodeKindTarget},
+			"pkg::x::Networking": {ID: "pkg::x::Networking", Label: "Networking", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Feature", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Feature", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "target::Util", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Util", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::x::Networking", Kind: graph.EdgeKindProduct, Weight: 2},
+		},
+	}
+}
+
+func TestBuildOrdersByLevelAndKeepsCyclesTogether(t *testing.T) {
+	m := Build(sampleGraph())
+	if got := strings.Join(m.Labels, ","); got != "Networking,Core,Util,Feature,App" {
+		t.Fatalf("unexpected order %s", got)
+	}
+	if got := m.Levels; got[0] != 0 || got[1] != 1 || got[2] != 1 || got[3] != 2 || got[4] != 3 {
+		t.Fatalf("unexpected levels %#v", got)
+	}
+	if m.Cells[1][0] != 2 {
+		t.Fatalf("expected weighted Core -> Networking cell, got %d", m.Cells[1][0])
+	}
+	if m.Cells[1][2] != 1 || m.Cells[2][1] != 1 {
+		t.Fatalf("expected Core/Util cycle cells, got %#v", m.Cells)
+	}
+	if m.AboveDiagonal() != 1 {
+		t.Fatalf("expected one mark above the diagonal, got %d", m.AboveDiagonal())
+	}
+}
+
+func TestAcyclicGraphHasNothingAboveDiagonal(t *testing.T) {
+	g := sampleGraph()
+	g.Edges = g.Edges[:3]
+	if n := Build(g).AboveDiagonal(); n != 0 {
+		t.Fatalf("expected no marks above the diagonal, got %d", n)
+	}
+}
+
+func TestTextMarksCells(t *testing.T) {
+	out, err := Text(Build(sampleGraph()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		"#  NODE        LEVEL  1 2 3 4 5",
+		"2  Core            1  x - ! . .",
+		"3  Util            1  . x - . .",
+		"(cycles): 1",
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+	if empty, _ := Text(Build(graph.Graph{})); empty != "(empty)" {
+		t.Fatalf("unexpected empty output %q", empty)
+	}
+}
+
+func TestCSVWritesCounts(t *testing.T) {
+	out, err := CSV(Build(sampleGraph()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if lines[0] != ",Networking,Core,Util,Feature,App" || lines[2] != "Core,2,,1,," {
+		t.Fatalf("unexpected CSV:\n%s", out)
+	}
+}
+
+func TestHTMLColorsCellsAndEscapes(t *testing.T) {
+	g := sampleGraph()
+	g.Nodes["target::App"] = graph.Node{ID: "target::App", Label: "<App>", Kind: graph.NodeKindTarget}
+	out, err := HTML(Build(g))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`<td class="cycle" title="Core → Util">1</td>`,
+		`<td class="dep" title="Core → Networking">2</td>`,
+		`&lt;App&gt;`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+	if strings.Contains(out, "<App>") {
+		t.Fatal("expected labels to be escaped")
+	}
+}
@@ -26,6 +26,9 @@ const (
 	KindGraphvizNotFound          Kind = "graphviz_not_found"
 	KindGraphvizRender            Kind = "graphviz_render_failed"
 	KindOutputWrite               Kind = "output_write_failed"
+	KindBaselineExceeded          Kind = "baseline_exceeded"
+	KindOutputStale               Kind = "output_stale"
+	KindNoPath                    Kind = "no_path"
 	KindRuntime                   Kind = "runtime_failed"
 )
 
@@ -79,6 +82,10 @@ func ExitCode(err error) int {
 	switch appErr.Kind {
 	case KindInvalidArgs, KindManifestNotFound, KindInputNotFound, KindAmbiguousInput, KindXcodeProjectNotFound, KindBazelWorkspaceNotFound:
 		return 1
+	case KindBaselineExceeded, KindOutputStale:
+		return 3
+	case KindNoPath:
+		return 4
 	default:
 		return 2
 	}
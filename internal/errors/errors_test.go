@@ -27,4 +27,13 @@ func TestErrorToExitCodeMapping(t *testing.T) {
 	if code := ExitCode(New(KindRuntime, "boom", errors.New("x"))); code != 2 {
 		t.Fatalf("expected code 2 for runtime, got %d", code)
 	}
+	if code := ExitCode(New(KindNoPath, "no path", nil)); code != 4 {
+		t.Fatalf("expected code 4 for no path, got %d", code)
+	}
+	if code := ExitCode(New(KindBaselineExceeded, "grew", nil)); code != 3 {
+		t.Fatalf("expected code 3 for baseline exceeded, got %d", code)
+	}
+	if code := ExitCode(New(KindOutputStale, "stale", nil)); code != 3 {
+		t.Fatalf("expected code 3 for stale output, got %d", code)
+	}
 }
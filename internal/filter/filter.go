@@ -0,0 +1,196 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// PatternUsage describes the --include and --exclude pattern syntax for help text.
+const PatternUsage = "glob over node labels and IDs (* and ? also match /), or regex:<expr>"
+
+// ModeUsage lists the supported --filter-mode values for help and error text.
+const ModeUsage = "drop|bridge"
+
+// KindUsage lists the node kinds accepted by --exclude-kind.
+var KindUsage = strings.Join([]string{string(graph.NodeKindTarget), string(graph.NodeKindExternalProduct)}, "|")
+
+// TypeUsage lists the target types accepted by --include-type and --exclude-type.
+var TypeUsage = targetTypeUsage()
+
+func targetTypeUsage() string {
+	names := make([]string, 0, len(graph.TargetTypes))
+	for _, targetType := range graph.TargetTypes {
+		names = append(names, string(targetType))
+	}
+	return strings.Join(names, "|")
+}
+
+// Spec holds the raw filter options.
+type Spec struct {
+	Include      []string
+	Exclude      []string
+	ExcludeKinds []string
+	IncludeTypes []string
+	ExcludeTypes []string
+	// Mode is drop or bridge; empty means drop.
+	Mode string
+}
+
+// Filter selects the nodes to keep after the graph is built.
+type Filter struct {
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+	excludeKinds map[graph.NodeKind]bool
+	includeTypes map[graph.TargetType]bool
+	excludeTypes map[graph.TargetType]bool
+	bridge       bool
+}
+
+// Parse validates spec and compiles its patterns.
+func Parse(spec Spec) (Filter, error) {
+	f := Filter{excludeKinds: map[graph.NodeKind]bool{}}
+	var err error
+	if f.include, err = compileAll("--include", spec.Include); err != nil {
+		return Filter{}, err
+	}
+	if f.exclude, err = compileAll("--exclude", spec.Exclude); err != nil {
+		return Filter{}, err
+	}
+	for _, kind := range spec.ExcludeKinds {
+		switch graph.NodeKind(kind) {
+		case graph.NodeKindTarget, graph.NodeKindExternalProduct:
+			f.excludeKinds[graph.NodeKind(kind)] = true
+		default:
+			return Filter{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("--exclude-kind %q must be one of: %s", kind, KindUsage), nil)
+		}
+	}
+	if f.includeTypes, err = parseTypes("--include-type", spec.IncludeTypes); err != nil {
+		return Filter{}, err
+	}
+	if f.excludeTypes, err = parseTypes("--exclude-type", spec.ExcludeTypes); err != nil {
+		return Filter{}, err
+	}
+	switch spec.Mode {
+	case "", "drop":
+	case "bridge":
+		f.bridge = true
+	default:
+		return Filter{}, apperrors.New(apperrors.KindInvalidArgs, "--filter-mode must be one of: "+ModeUsage, nil)
+	}
+	return f, nil
+}
+
+func parseTypes(flagName string, values []string) (map[graph.TargetType]bool, error) {
+	types := map[graph.TargetType]bool{}
+	for _, value := range values {
+		if !graph.IsTargetType(value) {
+			return nil, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s %q must be one of: %s", flagName, value, TypeUsage), nil)
+		}
+		types[graph.TargetType(value)] = true
+	}
+	return types, nil
+}
+
+func compileAll(flagName string, patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := Compile(pattern)
+		if err != nil {
+			return nil, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("invalid %s pattern %q", flagName, pattern), err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Compile turns a regex:<expr> pattern into its expression and a glob into an anchored
+// expression in which * matches any run of characters and ? any single character.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if expr, ok := strings.CutPrefix(pattern, "regex:"); ok {
+		return regexp.Compile(expr)
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// Active reports whether the filter removes anything.
+func (f Filter) Active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0 || len(f.excludeKinds) > 0 || len(f.includeTypes) > 0 || len(f.excludeTypes) > 0
+}
+
+// Keep reports whether node passes the filter: it matches an include pattern (when any
+// are given), matches no exclude pattern, and has no excluded kind. Patterns match the
+// label or the ID. Target types select among targets only; external products pass them.
+func (f Filter) Keep(node graph.Node) bool {
+	if f.excludeKinds[node.Kind] {
+		return false
+	}
+	if node.Kind == graph.NodeKindTarget {
+		if len(f.includeTypes) > 0 && !f.includeTypes[node.Type] {
+			return false
+		}
+		if f.excludeTypes[node.Type] {
+			return false
+		}
+	}
+	if len(f.include) > 0 && !matchesAny(f.include, node) {
+		return false
+	}
+	return !matchesAny(f.exclude, node)
+}
+
+func matchesAny(patterns []*regexp.Regexp, node graph.Node) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(node.Label) || pattern.MatchString(node.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply removes the nodes the filter rejects. In bridge mode, kept nodes that depended on
+// each other through removed nodes are connected directly.
+func (f Filter) Apply(g graph.Graph) graph.Graph {
+	if !f.Active() {
+		return g
+	}
+	if f.bridge {
+		return graph.BridgeNodes(g, f.Keep)
+	}
+	return graph.FilterNodes(g, f.Keep)
+}
@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func filterGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":               {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::NetworkingMocks":   {ID: "target::NetworkingMocks", Label: "NetworkingMocks", Kind: graph.NodeKindTarget},
+			"target::CoreTestSupport":   {ID: "target::CoreTestSupport", Label: "CoreTestSupport", Kind: graph.NodeKindTarget},
+			"target::Networking":        {ID: "target::Networking", Label: "Networking", Kind: graph.NodeKindTarget},
+			"bazel:://ios/core:Core":    {ID: "bazel:://ios/core:Core", Label: "//ios/core:Core", Kind: graph.NodeKindTarget},
+			"pkg::swift-nio::NIO":       {ID: "pkg::swift-nio::NIO", Label: "NIO", Kind: graph.NodeKindExternalProduct, Package: "swift-nio"},
+			"pkg::swift-log::Logging":   {ID: "pkg::swift-log::Logging", Label: "Logging", Kind: graph.NodeKindExternalProduct, Package: "swift-log"},
+			"target::NetworkingTestKit": {ID: "target::NetworkingTestKit", Label: "NetworkingTestKit", Kind: graph.NodeKindTarget},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::NetworkingMocks", Kind: graph.EdgeKindTarget},
+			{FromID: "target::NetworkingMocks", ToID: "target::Networking", Kind: graph.EdgeKindTarget},
+			{FromID: "target::NetworkingMocks", ToID: "target::CoreTestSupport", Kind: graph.EdgeKindTarget},
+			{FromID: "target::CoreTestSupport", ToID: "bazel:://ios/core:Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Networking", ToID: "pkg::swift-nio::NIO", Kind: graph.EdgeKindProduct},
+			{FromID: "target::Networking", ToID: "pkg::swift-log::Logging", Kind: graph.EdgeKindProduct},
+		},
+	}
+}
+
+func keptIDs(g graph.Graph) string {
+	return strings.Join(graph.SortedNodeIDs(g), ",")
+}
+
+func TestApplyDropsMatchedNodes(t *testing.T) {
+	f, err := Parse(Spec{Exclude: []string{"*Mocks", "*TestSupport"}, ExcludeKinds: []string{"external_product"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := f.Apply(filterGraph())
+	if got := keptIDs(g); got != "bazel:://ios/core:Core,target::App,target::Networking,target::NetworkingTestKit" {
+		t.Fatalf("unexpected nodes %s", got)
+	}
+	if len(g.Edges) != 0 {
+		t.Fatalf("expected every edge through dropped nodes to disappear, got %#v", g.Edges)
+	}
+}
+
+func TestApplyBridgesThroughMatchedNodes(t *testing.T) {
+	f, err := Parse(Spec{Exclude: []string{"*Mocks", "regex:TestSupport$"}, Mode: "bridge"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := f.Apply(filterGraph())
+	var edges []string
+	for _, e := range g.Edges {
+		edges = append(edges, e.FromID+"->"+e.ToID)
+	}
+	want := "target::App->bazel:://ios/core:Core,target::App->target::Networking,target::Networking->pkg::swift-log::Logging,target::Networking->pkg::swift-nio::NIO"
+	if strings.Join(edges, ",") != want {
+		t.Fatalf("unexpected bridged edges %v", edges)
+	}
+}
+
+func TestIncludePatternsMatchLabelsAndIDs(t *testing.T) {
+	f, err := Parse(Spec{Include: []string{"App", "//ios/*", "pkg::swift-nio::*", "Networking[!T]*"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := keptIDs(f.Apply(filterGraph())); got != "bazel:://ios/core:Core,pkg::swift-nio::NIO,target::App,target::NetworkingMocks" {
+		t.Fatalf("unexpected nodes %s", got)
+	}
+
+	inactive, _ := Parse(Spec{})
+	if inactive.Active() || len(inactive.Apply(filterGraph()).Nodes) != 8 {
+		t.Fatal("expected an empty spec to keep the graph")
+	}
+}
+
+func TestTargetTypesSelectTargetsOnly(t *testing.T) {
+	g := filterGraph()
+	for id, targetType := range map[string]graph.TargetType{
+		"target::App":             graph.TargetTypeApp,
+		"target::NetworkingMocks": graph.TargetTypeLibrary,
+		"target::CoreTestSupport": graph.TargetTypeLibrary,
+		"target::Networking":      graph.TargetTypeFramework,
+		"bazel:://ios/core:Core":  graph.TargetTypeLibrary,
+	} {
+		node := g.Nodes[id]
+		node.Type = targetType
+		g.Nodes[id] = node
+	}
+
+	f, err := Parse(Spec{IncludeTypes: []string{"app", "framework"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := keptIDs(f.Apply(g)); got != "pkg::swift-log::Logging,pkg::swift-nio::NIO,target::App,target::Networking" {
+		t.Fatalf("unexpected nodes %s", got)
+	}
+
+	f, _ = Parse(Spec{ExcludeTypes: []string{"library"}, Mode: "bridge"})
+	got := f.Apply(g)
+	if keptIDs(got) != "pkg::swift-log::Logging,pkg::swift-nio::NIO,target::App,target::Networking,target::NetworkingTestKit" {
+		t.Fatalf("unexpected nodes %s", keptIDs(got))
+	}
+	if len(got.Edges) != 3 || got.Edges[0].FromID != "target::App" || got.Edges[0].ToID != "target::Networking" {
+		t.Fatalf("expected App bridged to Networking, got %#v", got.Edges)
+	}
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []Spec{
+		{Include: []string{"regex:("}},
+		{Exclude: []string{"Foo[ab"}},
+		{Exclude: []string{""}},
+		{ExcludeKinds: []string{"module"}},
+		{IncludeTypes: []string{"application"}},
+		{ExcludeTypes: []string{"Test"}},
+		{Mode: "hide"},
+	} {
+		if _, err := Parse(spec); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args for %#v, got %v", spec, err)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package graph
+
+import "sort"
+
+// Successors returns the distinct direct dependencies of every node, sorted by ID.
+// Edges that reference unknown nodes are ignored.
+func Successors(g Graph) map[string][]string {
+	return adjacency(g, false)
+}
+
+// Predecessors returns the distinct direct dependents of every node, sorted by ID.
+// Edges that reference unknown nodes are ignored.
+func Predecessors(g Graph) map[string][]string {
+	return adjacency(g, true)
+}
+
+func adjacency(g Graph, reverse bool) map[string][]string {
+	seen := make(map[string]map[string]struct{}, len(g.Nodes))
+	out := make(map[string][]string, len(g.Nodes))
+	for id := range g.Nodes {
+		seen[id] = make(map[string]struct{})
+		out[id] = nil
+	}
+	for _, edge := range g.Edges {
+		if _, ok := g.Nodes[edge.FromID]; !ok {
+			continue
+		}
+		if _, ok := g.Nodes[edge.ToID]; !ok {
+			continue
+		}
+		from, to := edge.FromID, edge.ToID
+		if reverse {
+			from, to = to, from
+		}
+		if _, ok := seen[from][to]; ok {
+			continue
+		}
+		seen[from][to] = struct{}{}
+		out[from] = append(out[from], to)
+	}
+	for id := range out {
+		sort.Strings(out[id])
+	}
+	return out
+}
+
+// Reachable returns the IDs reachable from start by following adj, excluding start
+// itself unless it lies on a cycle.
+func Reachable(adj map[string][]string, start string) map[string]struct{} {
+	visited := make(map[string]struct{})
+	stack := append([]string(nil), adj[start]...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		visited[id] = struct{}{}
+		stack = append(stack, adj[id]...)
+	}
+	return visited
+}
+
+// StronglyConnectedComponents returns the strongly connected components of g.
+// Each component is sorted by node ID and components are ordered by their first ID.
+func StronglyConnectedComponents(g Graph) [][]string {
+	adj := Successors(g)
+	index := 0
+	indices := make(map[string]int, len(g.Nodes))
+	lowlink := make(map[string]int, len(g.Nodes))
+	onStack := make(map[string]bool, len(g.Nodes))
+	stack := make([]string, 0)
+	components := make([][]string, 0)
+
+	var strongConnect func(string)
+	strongConnect = func(id string) {
+		indices[id] = index
+		lowlink[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range adj[id] {
+			if _, ok := indices[next]; !ok {
+				strongConnect(next)
+				if lowlink[next] < lowlink[id] {
+					lowlink[id] = lowlink[next]
+				}
+			} else if onStack[next] && indices[next] < lowlink[id] {
+				lowlink[id] = indices[next]
+			}
+		}
+
+		if lowlink[id] != indices[id] {
+			return
+		}
+		component := make([]string, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+
+	for _, id := range SortedNodeIDs(g) {
+		if _, ok := indices[id]; !ok {
+			strongConnect(id)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+	return components
+}
+
+// Cycles returns the strongly connected components that contain a cycle: components
+// with more than one node and single nodes that depend on themselves.
+func Cycles(g Graph) [][]string {
+	adj := Successors(g)
+	cycles := make([][]string, 0)
+	for _, component := range StronglyConnectedComponents(g) {
+		if len(component) > 1 {
+			cycles = append(cycles, component)
+			continue
+		}
+		for _, next := range adj[component[0]] {
+			if next == component[0] {
+				cycles = append(cycles, component)
+				break
+			}
+		}
+	}
+	return cycles
+}
+
+// Condensation describes g with every strongly connected component collapsed to one vertex.
+type Condensation struct {
+	// Components lists the members of each component, as returned by StronglyConnectedComponents.
+	Components [][]string
+	// ComponentOf maps a node ID to its index in Components.
+	ComponentOf map[string]int
+	// Successors lists the distinct component indices each component depends on.
+	Successors [][]int
+}
+
+// Condense builds the acyclic component graph of g.
+func Condense(g Graph) Condensation {
+	components := StronglyConnectedComponents(g)
+	componentOf := make(map[string]int, len(g.Nodes))
+	for i, component := range components {
+		for _, id := range component {
+			componentOf[id] = i
+		}
+	}
+
+	successors := make([][]int, len(components))
+	seen := make([]map[int]struct{}, len(components))
+	for i := range seen {
+		seen[i] = make(map[int]struct{})
+	}
+	adj := Successors(g)
+	for _, id := range SortedNodeIDs(g) {
+		from := componentOf[id]
+		for _, next := range adj[id] {
+			to := componentOf[next]
+			if to == from {
+				continue
+			}
+			if _, ok := seen[from][to]; ok {
+				continue
+			}
+			seen[from][to] = struct{}{}
+			successors[from] = append(successors[from], to)
+		}
+	}
+	for i := range successors {
+		sort.Ints(successors[i])
+	}
+
+	return Condensation{Components: components, ComponentOf: componentOf, Successors: successors}
+}
+
+// Depths returns, for every node, the number of edges on the longest dependency chain
+// starting at that node. Nodes in the same cycle share a depth.
+func Depths(g Graph) map[string]int {
+	c := Condense(g)
+	componentDepth := c.componentDepths()
+	out := make(map[string]int, len(g.Nodes))
+	for id, component := range c.ComponentOf {
+		out[id] = componentDepth[component]
+	}
+	return out
+}
+
+func (c Condensation) componentDepths() []int {
+	depth := make([]int, len(c.Components))
+	done := make([]bool, len(c.Components))
+	var visit func(int) int
+	visit = func(i int) int {
+		if done[i] {
+			return depth[i]
+		}
+		best := 0
+		for _, next := range c.Successors[i] {
+			if d := visit(next) + 1; d > best {
+				best = d
+			}
+		}
+		depth[i] = best
+		done[i] = true
+		return best
+	}
+	for i := range c.Components {
+		visit(i)
+	}
+	return depth
+}
+
+// LongestChain returns the node IDs along the longest dependency chain in g. Cycles are
+// collapsed, so each strongly connected component contributes its lowest node ID.
+func LongestChain(g Graph) []string {
+	if len(g.Nodes) == 0 {
+		return nil
+	}
+	c := Condense(g)
+	depth := c.componentDepths()
+
+	start := 0
+	for i := range c.Components {
+		if depth[i] > depth[start] {
+			start = i
+		}
+	}
+
+	chain := []string{c.Components[start][0]}
+	for current := start; depth[current] > 0; {
+		for _, next := range c.Successors[current] {
+			if depth[next] == depth[current]-1 {
+				current = next
+				break
+			}
+		}
+		chain = append(chain, c.Components[current][0])
+	}
+	return chain
+}
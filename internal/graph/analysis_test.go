@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func analysisGraph() Graph {
+	return Graph{
+		Nodes: map[string]Node{
+			"a": {ID: "a", Label: "A", Kind: NodeKindTarget},
+			"b": {ID: "b", Label: "B", Kind: NodeKindTarget},
+			"c": {ID: "c", Label: "C", Kind: NodeKindTarget},
+			"d": {ID: "d", Label: "D", Kind: NodeKindTarget},
+			"x": {ID: "x", Label: "X", Kind: NodeKindExternalProduct},
+		},
+		Edges: []Edge{
+			{FromID: "a", ToID: "b", Kind: EdgeKindTarget},
+			{FromID: "a", ToID: "b", Kind: EdgeKindByName},
+			{FromID: "b", ToID: "c", Kind: EdgeKindTarget},
+			{FromID: "c", ToID: "b", Kind: EdgeKindTarget},
+			{FromID: "c", ToID: "x", Kind: EdgeKindProduct},
+			{FromID: "d", ToID: "x", Kind: EdgeKindProduct},
+		},
+	}
+}
+
+func TestSuccessorsAndPredecessorsAreDistinctAndSorted(t *testing.T) {
+	g := analysisGraph()
+	succ := Successors(g)
+	if !reflect.DeepEqual(succ["a"], []string{"b"}) {
+		t.Fatalf("expected a -> b once, got %#v", succ["a"])
+	}
+	pred := Predecessors(g)
+	if !reflect.DeepEqual(pred["x"], []string{"c", "d"}) {
+		t.Fatalf("unexpected predecessors of x: %#v", pred["x"])
+	}
+}
+
+func TestStronglyConnectedComponentsAndCycles(t *testing.T) {
+	g := analysisGraph()
+	components := StronglyConnectedComponents(g)
+	expected := [][]string{{"a"}, {"b", "c"}, {"d"}, {"x"}}
+	if !reflect.DeepEqual(components, expected) {
+		t.Fatalf("unexpected components: %#v", components)
+	}
+	cycles := Cycles(g)
+	if !reflect.DeepEqual(cycles, [][]string{{"b", "c"}}) {
+		t.Fatalf("unexpected cycles: %#v", cycles)
+	}
+}
+
+func TestCyclesIncludesSelfLoops(t *testing.T) {
+	g := Graph{
+		Nodes: map[string]Node{"a": {ID: "a"}},
+		Edges: []Edge{{FromID: "a", ToID: "a", Kind: EdgeKindTarget}},
+	}
+	if cycles := Cycles(g); !reflect.DeepEqual(cycles, [][]string{{"a"}}) {
+		t.Fatalf("expected self loop cycle, got %#v", cycles)
+	}
+}
+
+func TestDepthsCollapseCycles(t *testing.T) {
+	depths := Depths(analysisGraph())
+	expected := map[string]int{"a": 2, "b": 1, "c": 1, "d": 1, "x": 0}
+	if !reflect.DeepEqual(depths, expected) {
+		t.Fatalf("unexpected depths: %#v", depths)
+	}
+}
+
+func TestLongestChain(t *testing.T) {
+	chain := LongestChain(analysisGraph())
+	if !reflect.DeepEqual(chain, []string{"a", "b", "x"}) {
+		t.Fatalf("unexpected longest chain: %#v", chain)
+	}
+	if chain := LongestChain(Graph{}); chain != nil {
+		t.Fatalf("expected nil chain for empty graph, got %#v", chain)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	succ := Successors(analysisGraph())
+	reach := Reachable(succ, "a")
+	for _, id := range []string{"b", "c", "x"} {
+		if _, ok := reach[id]; !ok {
+			t.Fatalf("expected %s reachable from a", id)
+		}
+	}
+	if _, ok := reach["a"]; ok {
+		t.Fatal("did not expect a to reach itself")
+	}
+	if _, ok := Reachable(succ, "b")["b"]; !ok {
+		t.Fatal("expected b to reach itself through its cycle")
+	}
+}
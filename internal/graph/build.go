@@ -21,11 +21,11 @@ func byNameNodeID(name string) string {
 	return "name::" + name
 }
 
-func addNode(nodes map[string]Node, id, label string, kind NodeKind) {
+func addNode(nodes map[string]Node, id, label string, kind NodeKind, pkg string) {
 	if _, ok := nodes[id]; ok {
 		return
 	}
-	nodes[id] = Node{ID: id, Label: label, Kind: kind}
+	nodes[id] = Node{ID: id, Label: label, Kind: kind, Package: pkg}
 }
 
 func addEdge(edges *[]Edge, dedup map[string]struct{}, edge Edge) {
@@ -37,6 +37,22 @@ func addEdge(edges *[]Edge, dedup map[string]struct{}, edge Edge) {
 	*edges = append(*edges, edge)
 }
 
+// targetPath returns the target's source directory, applying SwiftPM's default layout
+// when the manifest does not set a custom path.
+func targetPath(target manifest.Target) string {
+	if target.Path != "" {
+		return target.Path
+	}
+	switch target.Type {
+	case "test":
+		return "Tests/" + target.Name
+	case "plugin":
+		return "Plugins/" + target.Name
+	default:
+		return "Sources/" + target.Name
+	}
+}
+
 func shouldIncludeTarget(target manifest.Target, includeTests bool) bool {
 	if includeTests {
 		return true
@@ -44,6 +60,28 @@ func shouldIncludeTarget(target manifest.Target, includeTests bool) bool {
 	return target.Type != "test"
 }
 
+// swiftPMTargetType maps a SwiftPM manifest target type to a TargetType.
+func swiftPMTargetType(nativeType string) TargetType {
+	switch nativeType {
+	case "regular":
+		return TargetTypeLibrary
+	case "executable":
+		return TargetTypeExecutable
+	case "test":
+		return TargetTypeTest
+	case "macro":
+		return TargetTypeMacro
+	case "plugin":
+		return TargetTypePlugin
+	case "binary":
+		return TargetTypeBinary
+	case "system":
+		return TargetTypeSystemLibrary
+	default:
+		return ""
+	}
+}
+
 // Build converts manifest targets and dependencies into a directed dependency graph.
 func Build(pkg manifest.Package, includeTests bool) (Graph, error) {
 	nodes := make(map[string]Node)
@@ -56,7 +94,10 @@ func Build(pkg manifest.Package, includeTests bool) (Graph, error) {
 			continue
 		}
 		localTargets[target.Name] = struct{}{}
-		addNode(nodes, targetNodeID(target.Name), target.Name, NodeKindTarget)
+		id := targetNodeID(target.Name)
+		if _, ok := nodes[id]; !ok {
+			nodes[id] = Node{ID: id, Label: target.Name, Kind: NodeKindTarget, Package: pkg.Name, Path: targetPath(target), Test: target.Type == "test", NativeType: target.Type, Type: swiftPMTargetType(target.Type)}
+		}
 	}
 
 	for _, target := range pkg.Targets {
@@ -74,7 +115,7 @@ func Build(pkg manifest.Package, includeTests bool) (Graph, error) {
 				toID := targetNodeID(dep.Name)
 				if _, ok := localTargets[dep.Name]; !ok {
 					toID = productNodeID(dep.Name, "")
-					addNode(nodes, toID, dep.Name, NodeKindExternalProduct)
+					addNode(nodes, toID, dep.Name, NodeKindExternalProduct, "")
 				}
 				addEdge(&edges, edgeDedup, Edge{FromID: fromID, ToID: toID, Kind: EdgeKindTarget})
 			case manifest.DependencyKindProduct:
@@ -82,7 +123,7 @@ func Build(pkg manifest.Package, includeTests bool) (Graph, error) {
 					continue
 				}
 				toID := productNodeID(dep.Name, dep.Package)
-				addNode(nodes, toID, dep.Name, NodeKindExternalProduct)
+				addNode(nodes, toID, dep.Name, NodeKindExternalProduct, dep.Package)
 				addEdge(&edges, edgeDedup, Edge{FromID: fromID, ToID: toID, Kind: EdgeKindProduct})
 			case manifest.DependencyKindByName:
 				if dep.Name == "" {
@@ -92,7 +133,7 @@ func Build(pkg manifest.Package, includeTests bool) (Graph, error) {
 				if _, ok := localTargets[dep.Name]; ok {
 					toID = targetNodeID(dep.Name)
 				} else {
-					addNode(nodes, toID, dep.Name, NodeKindExternalProduct)
+					addNode(nodes, toID, dep.Name, NodeKindExternalProduct, "")
 				}
 				addEdge(&edges, edgeDedup, Edge{FromID: fromID, ToID: toID, Kind: EdgeKindByName})
 			}
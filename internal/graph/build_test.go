@@ -54,6 +54,23 @@ func TestBuildGraphProductDependencies(t *testing.T) {
 	if n.Kind != NodeKindExternalProduct {
 		t.Fatalf("expected external product kind, got %s", n.Kind)
 	}
+	if n.Package != "alamofire" {
+		t.Fatalf("expected product package alamofire, got %q", n.Package)
+	}
+	if app := g.Nodes[targetNodeID("App")]; app.Package != "Sample" {
+		t.Fatalf("expected local target package Sample, got %q", app.Package)
+	} else if app.Path != "Sources/App" {
+		t.Fatalf("expected default source path Sources/App, got %q", app.Path)
+	}
+}
+
+func TestTargetPathPrefersManifestPath(t *testing.T) {
+	if got := targetPath(manifest.Target{Name: "Core", Path: "Modules/Core"}); got != "Modules/Core" {
+		t.Fatalf("expected manifest path, got %q", got)
+	}
+	if got := targetPath(manifest.Target{Name: "CoreTests", Type: "test"}); got != "Tests/CoreTests" {
+		t.Fatalf("expected default test path, got %q", got)
+	}
 }
 
 func TestBuildGraphByNameResolution(t *testing.T) {
@@ -99,8 +116,10 @@ func TestBuildGraphExcludeAndIncludeTests(t *testing.T) {
 	if err != nil {
 		t.Fatalf("build with tests failed: %v", err)
 	}
-	if _, ok := withTests.Nodes[targetNodeID("AppTests")]; !ok {
+	if node, ok := withTests.Nodes[targetNodeID("AppTests")]; !ok {
 		t.Fatal("expected AppTests node when includeTests=true")
+	} else if !node.Test || node.NativeType != "test" {
+		t.Fatalf("expected AppTests node marked as test, got %#v", node)
 	}
 }
 
@@ -156,3 +175,21 @@ func TestBuildGraphDeterministicOrdering(t *testing.T) {
 		}
 	}
 }
+
+func TestSwiftPMTargetTypeNormalizesManifestTypes(t *testing.T) {
+	cases := map[string]TargetType{
+		"regular":    TargetTypeLibrary,
+		"executable": TargetTypeExecutable,
+		"test":       TargetTypeTest,
+		"macro":      TargetTypeMacro,
+		"plugin":     TargetTypePlugin,
+		"binary":     TargetTypeBinary,
+		"system":     TargetTypeSystemLibrary,
+		"unknown":    "",
+	}
+	for nativeType, want := range cases {
+		if got := swiftPMTargetType(nativeType); got != want {
+			t.Fatalf("swiftPMTargetType(%q) = %q, want %q", nativeType, got, want)
+		}
+	}
+}
@@ -9,6 +9,50 @@ const (
 	NodeKindExternalProduct NodeKind = "external_product"
 )
 
+// TargetType is the normalized type of a target node, derived from its NativeType.
+type TargetType string
+
+const (
+	TargetTypeLibrary       TargetType = "library"
+	TargetTypeExecutable    TargetType = "executable"
+	TargetTypeTest          TargetType = "test"
+	TargetTypeUITest        TargetType = "ui_test"
+	TargetTypeApp           TargetType = "app"
+	TargetTypeAppExtension  TargetType = "app_extension"
+	TargetTypeFramework     TargetType = "framework"
+	TargetTypeMacro         TargetType = "macro"
+	TargetTypePlugin        TargetType = "plugin"
+	TargetTypeBinary        TargetType = "binary"
+	TargetTypeSystemLibrary TargetType = "system_library"
+	TargetTypeAggregate     TargetType = "aggregate"
+)
+
+// TargetTypes lists every target type in a stable order.
+var TargetTypes = []TargetType{
+	TargetTypeApp,
+	TargetTypeAppExtension,
+	TargetTypeFramework,
+	TargetTypeLibrary,
+	TargetTypeExecutable,
+	TargetTypeMacro,
+	TargetTypePlugin,
+	TargetTypeBinary,
+	TargetTypeSystemLibrary,
+	TargetTypeTest,
+	TargetTypeUITest,
+	TargetTypeAggregate,
+}
+
+// IsTargetType reports whether value names a TargetType.
+func IsTargetType(value string) bool {
+	for _, targetType := range TargetTypes {
+		if string(targetType) == value {
+			return true
+		}
+	}
+	return false
+}
+
 type EdgeKind string
 
 const (
@@ -21,12 +65,29 @@ type Node struct {
 	ID    string
 	Label string
 	Kind  NodeKind
+	// Package names the SwiftPM package, Xcode package identity, or Bazel repository
+	// that provides the node, when known.
+	Package string
+	// Project names the Xcode project that defines a target node.
+	Project string
+	// Path is the target's source directory relative to its package, project, or workspace root.
+	Path string
+	// Test marks test targets; they are only present with --include-tests.
+	Test bool
+	// NativeType is the target type reported by the source: the SwiftPM target type,
+	// the Xcode product type, or the Bazel rule kind.
+	NativeType string
+	// Type is the normalized target type; empty for external products and native types
+	// that have no equivalent.
+	Type TargetType
 }
 
 type Edge struct {
 	FromID string
 	ToID   string
 	Kind   EdgeKind
+	// Weight counts the original edges merged into this one; zero means one.
+	Weight int
 }
 
 type Graph struct {
@@ -0,0 +1,184 @@
+package graph
+
+// PackageNodeID returns the ID of the node that stands for a whole external package.
+func PackageNodeID(pkg string) string {
+	return "pkg::" + pkg
+}
+
+// HideExternal removes external product nodes and every edge that touches them.
+func HideExternal(g Graph) Graph {
+	return keepNodes(g, func(node Node) bool { return node.Kind != NodeKindExternalProduct })
+}
+
+// HideTests removes test targets and every edge that touches them.
+func HideTests(g Graph) Graph {
+	return keepNodes(g, func(node Node) bool { return !node.Test })
+}
+
+// Neighborhood keeps the focus node, the nodes reachable from it within depth
+// dependency hops, the nodes that reach it within depth hops, and the edges among them.
+// A depth of zero or less means unlimited.
+func Neighborhood(g Graph, focus string, depth int) Graph {
+	keep := make(map[string]struct{})
+	for _, adj := range []map[string][]string{Successors(g), Predecessors(g)} {
+		level := []string{focus}
+		seen := map[string]struct{}{focus: {}}
+		for hops := 0; len(level) > 0 && (depth <= 0 || hops < depth); hops++ {
+			var next []string
+			for _, id := range level {
+				for _, neighbor := range adj[id] {
+					if _, ok := seen[neighbor]; !ok {
+						seen[neighbor] = struct{}{}
+						next = append(next, neighbor)
+					}
+				}
+			}
+			level = next
+		}
+		for id := range seen {
+			keep[id] = struct{}{}
+		}
+	}
+	return keepNodes(g, func(node Node) bool {
+		_, ok := keep[node.ID]
+		return ok
+	})
+}
+
+// FilterNodes returns the subgraph of the nodes accepted by keep and the edges between
+// them.
+func FilterNodes(g Graph, keep func(Node) bool) Graph {
+	return keepNodes(g, keep)
+}
+
+// BridgeNodes removes the nodes rejected by keep but preserves transitive connectivity:
+// a kept node that reached another kept node only through removed nodes gets a direct
+// edge to it, with the kind of the last edge on the way. Bridged edges that duplicate an
+// existing edge are dropped.
+func BridgeNodes(g Graph, keep func(Node) bool) Graph {
+	out := keepNodes(g, keep)
+	outgoing := make(map[string][]Edge)
+	for _, edge := range SortedEdges(g) {
+		outgoing[edge.FromID] = append(outgoing[edge.FromID], edge)
+	}
+	removed := func(id string) bool {
+		_, known := g.Nodes[id]
+		_, kept := out.Nodes[id]
+		return known && !kept
+	}
+	seen := make(map[string]struct{}, len(out.Edges))
+	for _, edge := range out.Edges {
+		seen[EdgeKey(edge)] = struct{}{}
+	}
+
+	for _, id := range SortedNodeIDs(out) {
+		visited := make(map[string]struct{})
+		var queue []string
+		for _, edge := range outgoing[id] {
+			if _, ok := visited[edge.ToID]; !ok && removed(edge.ToID) {
+				visited[edge.ToID] = struct{}{}
+				queue = append(queue, edge.ToID)
+			}
+		}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, edge := range outgoing[current] {
+				if removed(edge.ToID) {
+					if _, ok := visited[edge.ToID]; !ok {
+						visited[edge.ToID] = struct{}{}
+						queue = append(queue, edge.ToID)
+					}
+					continue
+				}
+				if _, ok := out.Nodes[edge.ToID]; !ok || edge.ToID == id {
+					continue
+				}
+				bridged := Edge{FromID: id, ToID: edge.ToID, Kind: edge.Kind}
+				if _, ok := seen[EdgeKey(bridged)]; ok {
+					continue
+				}
+				seen[EdgeKey(bridged)] = struct{}{}
+				out.Edges = append(out.Edges, bridged)
+			}
+		}
+	}
+
+	out.Edges = SortedEdges(out)
+	return out
+}
+
+// keepNodes returns the subgraph of the nodes accepted by keep and the edges between them.
+func keepNodes(g Graph, keep func(Node) bool) Graph {
+	nodes := make(map[string]Node, len(g.Nodes))
+	for id, node := range g.Nodes {
+		if keep(node) {
+			nodes[id] = node
+		}
+	}
+
+	edges := make([]Edge, 0, len(g.Edges))
+	for _, edge := range g.Edges {
+		_, fromOK := nodes[edge.FromID]
+		_, toOK := nodes[edge.ToID]
+		if fromOK && toOK {
+			edges = append(edges, edge)
+		}
+	}
+
+	out := Graph{Nodes: nodes, Edges: edges}
+	out.Edges = SortedEdges(out)
+	return out
+}
+
+// CollapsePackages merges all external products of one package into a single package
+// node. Edges that end up connecting the same nodes with the same kind are merged, and
+// their Weight records how many product edges they replace.
+func CollapsePackages(g Graph) Graph {
+	nodes := make(map[string]Node, len(g.Nodes))
+	remap := make(map[string]string, len(g.Nodes))
+	for _, id := range SortedNodeIDs(g) {
+		node := g.Nodes[id]
+		if node.Kind != NodeKindExternalProduct || node.Package == "" {
+			nodes[id] = node
+			remap[id] = id
+			continue
+		}
+		pkgID := PackageNodeID(node.Package)
+		remap[id] = pkgID
+		if _, ok := nodes[pkgID]; !ok {
+			nodes[pkgID] = Node{ID: pkgID, Label: node.Package, Kind: NodeKindExternalProduct, Package: node.Package}
+		}
+	}
+
+	index := make(map[string]int, len(g.Edges))
+	edges := make([]Edge, 0, len(g.Edges))
+	for _, edge := range SortedEdges(g) {
+		merged := Edge{FromID: mappedID(remap, edge.FromID), ToID: mappedID(remap, edge.ToID), Kind: edge.Kind, Weight: edgeWeight(edge)}
+		key := EdgeKey(merged)
+		if i, ok := index[key]; ok {
+			edges[i].Weight += merged.Weight
+			continue
+		}
+		index[key] = len(edges)
+		edges = append(edges, merged)
+	}
+
+	out := Graph{Nodes: nodes, Edges: edges}
+	out.Edges = SortedEdges(out)
+	return out
+}
+
+func mappedID(remap map[string]string, id string) string {
+	if mapped, ok := remap[id]; ok {
+		return mapped
+	}
+	return id
+}
+
+func edgeWeight(e Edge) int {
+	if e.Weight <= 0 {
+		return 1
+	}
+	return e.Weight
+}
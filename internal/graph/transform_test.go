@@ -0,0 +1,139 @@
+package graph
+
+import "testing"
+
+func packagesGraph() Graph {
+	return Graph{
+		Nodes: map[string]Node{
+			"target::App":         {ID: "target::App", Label: "App", Kind: NodeKindTarget, Package: "Sample"},
+			"target::Core":        {ID: "target::Core", Label: "Core", Kind: NodeKindTarget, Package: "Sample"},
+			"pkg::nio::NIO":       {ID: "pkg::nio::NIO", Label: "NIO", Kind: NodeKindExternalProduct, Package: "nio"},
+			"pkg::nio::NIOHTTP1":  {ID: "pkg::nio::NIOHTTP1", Label: "NIOHTTP1", Kind: NodeKindExternalProduct, Package: "nio"},
+			"product::Standalone": {ID: "product::Standalone", Label: "Standalone", Kind: NodeKindExternalProduct},
+		},
+		Edges: []Edge{
+			{FromID: "target::App", ToID: "target::Core", Kind: EdgeKindTarget},
+			{FromID: "target::App", ToID: "pkg::nio::NIO", Kind: EdgeKindProduct},
+			{FromID: "target::App", ToID: "pkg::nio::NIOHTTP1", Kind: EdgeKindProduct},
+			{FromID: "target::Core", ToID: "pkg::nio::NIO", Kind: EdgeKindProduct},
+			{FromID: "target::Core", ToID: "product::Standalone", Kind: EdgeKindTarget},
+		},
+	}
+}
+
+func TestCollapsePackagesMergesProductsWithMultiplicity(t *testing.T) {
+	g := CollapsePackages(packagesGraph())
+
+	if _, ok := g.Nodes["pkg::nio::NIO"]; ok {
+		t.Fatal("expected product nodes to be merged")
+	}
+	pkgNode, ok := g.Nodes["pkg::nio"]
+	if !ok || pkgNode.Label != "nio" || pkgNode.Kind != NodeKindExternalProduct {
+		t.Fatalf("unexpected package node: %#v", pkgNode)
+	}
+	if _, ok := g.Nodes["product::Standalone"]; !ok {
+		t.Fatal("expected products without package to be kept")
+	}
+
+	weights := make(map[string]int)
+	for _, e := range g.Edges {
+		weights[e.FromID+"->"+e.ToID] = e.Weight
+	}
+	if weights["target::App->pkg::nio"] != 2 {
+		t.Fatalf("expected App -> nio weight 2, got %#v", weights)
+	}
+	if weights["target::Core->pkg::nio"] != 1 {
+		t.Fatalf("expected Core -> nio weight 1, got %#v", weights)
+	}
+	if len(g.Edges) != 4 {
+		t.Fatalf("expected 4 edges, got %#v", g.Edges)
+	}
+}
+
+func TestHideExternalDropsExternalNodesAndEdges(t *testing.T) {
+	g := HideExternal(packagesGraph())
+	if len(g.Nodes) != 2 {
+		t.Fatalf("expected only targets, got %#v", g.Nodes)
+	}
+	if len(g.Edges) != 1 || g.Edges[0].ToID != "target::Core" {
+		t.Fatalf("unexpected edges: %#v", g.Edges)
+	}
+}
+
+func TestHideTestsDropsTestTargets(t *testing.T) {
+	g := packagesGraph()
+	g.Nodes["target::CoreTests"] = Node{ID: "target::CoreTests", Label: "CoreTests", Kind: NodeKindTarget, Test: true}
+	g.Edges = append(g.Edges, Edge{FromID: "target::CoreTests", ToID: "target::Core", Kind: EdgeKindTarget})
+
+	out := HideTests(g)
+	if _, ok := out.Nodes["target::CoreTests"]; ok || len(out.Nodes) != 5 || len(out.Edges) != 5 {
+		t.Fatalf("unexpected graph without tests: %#v", out)
+	}
+}
+
+func TestNeighborhoodLimitsHopsInBothDirections(t *testing.T) {
+	g := Graph{
+		Nodes: map[string]Node{
+			"a": {ID: "a", Label: "A", Kind: NodeKindTarget},
+			"b": {ID: "b", Label: "B", Kind: NodeKindTarget},
+			"c": {ID: "c", Label: "C", Kind: NodeKindTarget},
+			"d": {ID: "d", Label: "D", Kind: NodeKindTarget},
+			"x": {ID: "x", Label: "X", Kind: NodeKindTarget},
+		},
+		Edges: []Edge{
+			{FromID: "a", ToID: "b", Kind: EdgeKindTarget},
+			{FromID: "b", ToID: "c", Kind: EdgeKindTarget},
+			{FromID: "c", ToID: "d", Kind: EdgeKindTarget},
+			{FromID: "x", ToID: "d", Kind: EdgeKindTarget},
+		},
+	}
+
+	out := Neighborhood(g, "b", 1)
+	if got := SortedNodeIDs(out); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("unexpected depth-1 neighborhood %v", got)
+	}
+	if len(out.Edges) != 2 {
+		t.Fatalf("unexpected edges %#v", out.Edges)
+	}
+
+	// Dependents of dependencies are not part of the neighborhood.
+	if got := SortedNodeIDs(Neighborhood(g, "b", 0)); len(got) != 4 || got[3] != "d" {
+		t.Fatalf("unexpected unlimited neighborhood %v", got)
+	}
+}
+
+func TestBridgeNodesPreservesTransitiveDependencies(t *testing.T) {
+	g := Graph{
+		Nodes: map[string]Node{
+			"target::App":      {ID: "target::App", Label: "App", Kind: NodeKindTarget},
+			"target::AppMocks": {ID: "target::AppMocks", Label: "AppMocks", Kind: NodeKindTarget},
+			"target::Support":  {ID: "target::Support", Label: "Support", Kind: NodeKindTarget},
+			"target::Core":     {ID: "target::Core", Label: "Core", Kind: NodeKindTarget},
+			"pkg::nio::NIO":    {ID: "pkg::nio::NIO", Label: "NIO", Kind: NodeKindExternalProduct},
+		},
+		Edges: []Edge{
+			{FromID: "target::App", ToID: "target::AppMocks", Kind: EdgeKindTarget},
+			{FromID: "target::App", ToID: "target::Core", Kind: EdgeKindTarget},
+			{FromID: "target::AppMocks", ToID: "target::Support", Kind: EdgeKindTarget},
+			{FromID: "target::Support", ToID: "target::Core", Kind: EdgeKindTarget},
+			{FromID: "target::Support", ToID: "pkg::nio::NIO", Kind: EdgeKindProduct},
+			{FromID: "target::Support", ToID: "target::AppMocks", Kind: EdgeKindTarget},
+		},
+	}
+	keep := func(node Node) bool { return node.ID != "target::AppMocks" && node.ID != "target::Support" }
+
+	dropped := FilterNodes(g, keep)
+	if len(dropped.Nodes) != 3 || len(dropped.Edges) != 1 {
+		t.Fatalf("unexpected dropped graph: %#v", dropped)
+	}
+
+	bridged := BridgeNodes(g, keep)
+	got := make([]string, 0, len(bridged.Edges))
+	for _, e := range bridged.Edges {
+		got = append(got, EdgeKey(e))
+	}
+	want := []string{"product|target::App|pkg::nio::NIO", "target|target::App|target::Core"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected bridged edges %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,127 @@
+package graphviz
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+const renderTimeout = 30 * time.Second
+
+// Formats lists the Graphviz output formats Export supports. "json" is Graphviz's own
+// layout JSON (-Tjson).
+var Formats = []string{"png", "svg", "pdf", "jpg", "webp", "json"}
+
+// Engines lists the Graphviz layout engines Export can run.
+var Engines = []string{"dot", "neato", "fdp", "sfdp", "circo"}
+
+// DefaultEngine is the layout engine used when a request names none.
+const DefaultEngine = "dot"
+
+// Request describes one Graphviz rendering.
+type Request struct {
+	// Source is the DOT source to lay out.
+	Source string
+	// OutputPath is the file to write; an empty path makes Export a no-op.
+	OutputPath string
+	// Format is one of Formats.
+	Format string
+	// Engine is one of Engines; empty means DefaultEngine.
+	Engine string
+}
+
+var lookPath = exec.LookPath
+
+var runGraphviz = func(ctx context.Context, engine, format, dotSource, outputPath string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, engine, "-T"+format, "-o", outputPath)
+	cmd.Stdin = strings.NewReader(dotSource)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stderr.Bytes(), err
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFormat reports whether format is a supported Graphviz output format.
+func IsFormat(format string) bool {
+	return contains(Formats, format)
+}
+
+// IsEngine reports whether engine is a supported layout engine.
+func IsEngine(engine string) bool {
+	return contains(Engines, engine)
+}
+
+// FormatForPath infers the output format from the extension of outputPath.
+func FormatForPath(outputPath string) (string, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(outputPath), "."))
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+	if !IsFormat(ext) {
+		return "", false
+	}
+	return ext, true
+}
+
+// Export renders req.Source into req.OutputPath using the requested Graphviz layout
+// engine and output format.
+func Export(ctx context.Context, req Request) error {
+	if req.OutputPath == "" {
+		return nil
+	}
+	engine := req.Engine
+	if engine == "" {
+		engine = DefaultEngine
+	}
+	if !IsEngine(engine) {
+		return apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("graphviz layout engine must be one of: %s", strings.Join(Engines, "|")), nil)
+	}
+	if !IsFormat(req.Format) {
+		return apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("graphviz output format must be one of: %s", strings.Join(Formats, "|")), nil)
+	}
+	if _, err := lookPath(engine); err != nil {
+		return apperrors.New(apperrors.KindGraphvizNotFound, fmt.Sprintf("graphviz '%s' binary not found in PATH", engine), err)
+	}
+
+	dir := filepath.Dir(req.OutputPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return apperrors.New(apperrors.KindOutputWrite, fmt.Sprintf("failed creating %s output directory %s", req.Format, dir), err)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, renderTimeout)
+	defer cancel()
+
+	stderr, err := runGraphviz(timeoutCtx, engine, req.Format, req.Source, req.OutputPath)
+	if err != nil {
+		if timeoutCtx.Err() == context.DeadlineExceeded {
+			return apperrors.New(apperrors.KindGraphvizRender, "graphviz rendering timed out", timeoutCtx.Err())
+		}
+		detail := strings.TrimSpace(string(stderr))
+		if detail == "" {
+			detail = err.Error()
+		}
+		return apperrors.New(apperrors.KindGraphvizRender, fmt.Sprintf("graphviz rendering failed: %s", detail), err)
+	}
+	return nil
+}
+
+// WritePNG renders dot source into a PNG file using the dot layout engine.
+func WritePNG(ctx context.Context, dotSource, outputPath string) error {
+	return Export(ctx, Request{Source: dotSource, OutputPath: outputPath, Format: "png"})
+}
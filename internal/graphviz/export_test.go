@@ -0,0 +1,151 @@
+package graphviz
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+func TestWritePNGNoopWhenPathEmpty(t *testing.T) {
+	if err := WritePNG(context.Background(), "digraph {}", ""); err != nil {
+		t.Fatalf("expected nil error for empty output path, got %v", err)
+	}
+}
+
+func TestWritePNGGraphvizNotFound(t *testing.T) {
+	oldLookPath := lookPath
+	oldRunGraphviz := runGraphviz
+	lookPath = func(string) (string, error) { return "", errors.New("missing") }
+	runGraphviz = func(context.Context, string, string, string, string) ([]byte, error) { return nil, nil }
+	t.Cleanup(func() {
+		lookPath = oldLookPath
+		runGraphviz = oldRunGraphviz
+	})
+
+	err := WritePNG(context.Background(), "digraph {}", filepath.Join(t.TempDir(), "out.png"))
+	if err == nil {
+		t.Fatal("expected graphviz missing error")
+	}
+	if !apperrors.IsKind(err, apperrors.KindGraphvizNotFound) {
+		t.Fatalf("expected graphviz not found kind, got %v", err)
+	}
+}
+
+func TestWritePNGCommandFailureIncludesStderr(t *testing.T) {
+	oldLookPath := lookPath
+	oldRunGraphviz := runGraphviz
+	lookPath = func(string) (string, error) { return "/usr/bin/dot", nil }
+	runGraphviz = func(context.Context, string, string, string, string) ([]byte, error) {
+		return []byte("syntax error near"), errors.New("exit 1")
+	}
+	t.Cleanup(func() {
+		lookPath = oldLookPath
+		runGraphviz = oldRunGraphviz
+	})
+
+	err := WritePNG(context.Background(), "bad", filepath.Join(t.TempDir(), "out.png"))
+	if err == nil {
+		t.Fatal("expected render error")
+	}
+	if !apperrors.IsKind(err, apperrors.KindGraphvizRender) {
+		t.Fatalf("expected graphviz render kind, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "syntax error near") {
+		t.Fatalf("expected stderr details in error, got %q", err.Error())
+	}
+}
+
+func TestWritePNGSuccessCreatesOutputDirectory(t *testing.T) {
+	oldLookPath := lookPath
+	oldRunGraphviz := runGraphviz
+	lookPath = func(string) (string, error) { return "/usr/bin/dot", nil }
+	runGraphviz = func(_ context.Context, _, _, _ string, outputPath string) ([]byte, error) {
+		if err := os.WriteFile(outputPath, []byte("png"), 0o644); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	t.Cleanup(func() {
+		lookPath = oldLookPath
+		runGraphviz = oldRunGraphviz
+	})
+
+	outputPath := filepath.Join(t.TempDir(), "nested", "graph.png")
+	if err := WritePNG(context.Background(), "digraph {}", outputPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Fatalf("expected png file to exist: %v", err)
+	}
+}
+
+func TestExportPassesEngineAndFormat(t *testing.T) {
+	oldLookPath := lookPath
+	oldRunGraphviz := runGraphviz
+	var looked, gotEngine, gotFormat string
+	lookPath = func(name string) (string, error) {
+		looked = name
+		return "/usr/bin/" + name, nil
+	}
+	runGraphviz = func(_ context.Context, engine, format, _, _ string) ([]byte, error) {
+		gotEngine, gotFormat = engine, format
+		return nil, nil
+	}
+	t.Cleanup(func() {
+		lookPath = oldLookPath
+		runGraphviz = oldRunGraphviz
+	})
+
+	err := Export(context.Background(), Request{Source: "digraph {}", OutputPath: filepath.Join(t.TempDir(), "deps.svg"), Format: "svg", Engine: "sfdp"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if looked != "sfdp" || gotEngine != "sfdp" || gotFormat != "svg" {
+		t.Fatalf("expected sfdp -Tsvg, got lookPath=%q engine=%q format=%q", looked, gotEngine, gotFormat)
+	}
+
+	if err := Export(context.Background(), Request{Source: "digraph {}", OutputPath: filepath.Join(t.TempDir(), "deps.pdf"), Format: "pdf"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotEngine != "dot" || gotFormat != "pdf" {
+		t.Fatalf("expected default dot engine, got engine=%q format=%q", gotEngine, gotFormat)
+	}
+}
+
+func TestExportRejectsUnknownEngineAndFormat(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "deps.out")
+	for _, req := range []Request{
+		{Source: "digraph {}", OutputPath: outputPath, Format: "png", Engine: "twopi2"},
+		{Source: "digraph {}", OutputPath: outputPath, Format: "bmp"},
+	} {
+		if err := Export(context.Background(), req); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %#v, got %v", req, err)
+		}
+	}
+}
+
+func TestFormatForPath(t *testing.T) {
+	cases := map[string]string{
+		"deps.svg":        "svg",
+		"out/deps.PDF":    "pdf",
+		"deps.jpeg":       "jpg",
+		"deps.jpg":        "jpg",
+		"deps.webp":       "webp",
+		"deps.json":       "json",
+		"deps.png":        "png",
+		"deps.dot":        "",
+		"deps":            "",
+		"archive.tar.svg": "svg",
+	}
+	for path, want := range cases {
+		got, ok := FormatForPath(path)
+		if got != want || ok != (want != "") {
+			t.Fatalf("FormatForPath(%q) = %q, %v; want %q", path, got, ok, want)
+		}
+	}
+}
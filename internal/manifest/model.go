@@ -11,6 +11,7 @@ type Package struct {
 type Target struct {
 	Name         string             `json:"name"`
 	Type         string             `json:"type"`
+	Path         string             `json:"path"`
 	Dependencies []TargetDependency `json:"dependencies"`
 }
 
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// Report holds whole-graph and per-target dependency statistics.
+type Report struct {
+	Summary Summary         `json:"summary"`
+	Targets []TargetMetrics `json:"targets"`
+}
+
+// Summary holds whole-graph statistics.
+type Summary struct {
+	Nodes            int      `json:"nodes"`
+	Edges            int      `json:"edges"`
+	Targets          int      `json:"targets"`
+	ExternalProducts int      `json:"external_products"`
+	Density          float64  `json:"density"`
+	Components       int      `json:"strongly_connected_components"`
+	Cycles           int      `json:"cycles"`
+	LongestChain     []string `json:"longest_chain"`
+}
+
+// TargetMetrics holds coupling statistics for one target node.
+type TargetMetrics struct {
+	ID                   string  `json:"id"`
+	Label                string  `json:"label"`
+	FanIn                int     `json:"fan_in"`
+	FanOut               int     `json:"fan_out"`
+	TransitiveDeps       int     `json:"transitive_dependencies"`
+	TransitiveDependents int     `json:"transitive_dependents"`
+	Instability          float64 `json:"instability"`
+	Depth                int     `json:"depth"`
+}
+
+// Compute derives the metrics report from a dependency graph.
+//
+// Fan-in (Ca) and fan-out (Ce) count distinct neighbours regardless of edge kind.
+// Instability is Ce/(Ca+Ce), or 0 for isolated targets. Depth is the number of
+// edges on the longest dependency chain below a target, with cycles collapsed.
+func Compute(g graph.Graph) Report {
+	succ := graph.Successors(g)
+	pred := graph.Predecessors(g)
+	depths := graph.Depths(g)
+
+	pairs := 0
+	for id, next := range succ {
+		for _, to := range next {
+			if to != id {
+				pairs++
+			}
+		}
+	}
+
+	summary := Summary{
+		Nodes:      len(g.Nodes),
+		Edges:      len(g.Edges),
+		Components: len(graph.StronglyConnectedComponents(g)),
+		Cycles:     len(graph.Cycles(g)),
+	}
+	if n := len(g.Nodes); n > 1 {
+		summary.Density = float64(pairs) / float64(n*(n-1))
+	}
+	for _, id := range graph.LongestChain(g) {
+		summary.LongestChain = append(summary.LongestChain, g.Nodes[id].Label)
+	}
+
+	targets := make([]TargetMetrics, 0)
+	for _, id := range graph.SortedNodeIDs(g) {
+		node := g.Nodes[id]
+		if node.Kind == graph.NodeKindExternalProduct {
+			summary.ExternalProducts++
+			continue
+		}
+		summary.Targets++
+
+		m := TargetMetrics{
+			ID:                   id,
+			Label:                node.Label,
+			FanIn:                len(pred[id]),
+			FanOut:               len(succ[id]),
+			TransitiveDeps:       countExcluding(graph.Reachable(succ, id), id),
+			TransitiveDependents: countExcluding(graph.Reachable(pred, id), id),
+			Depth:                depths[id],
+		}
+		if total := m.FanIn + m.FanOut; total > 0 {
+			m.Instability = float64(m.FanOut) / float64(total)
+		}
+		targets = append(targets, m)
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Label != targets[j].Label {
+			return targets[i].Label < targets[j].Label
+		}
+		return targets[i].ID < targets[j].ID
+	})
+
+	return Report{Summary: summary, Targets: targets}
+}
+
+func countExcluding(set map[string]struct{}, id string) int {
+	if _, ok := set[id]; ok {
+		return len(set) - 1
+	}
+	return len(set)
+}
+
+// Text renders a metrics report as aligned plain-text tables.
+func Text(r Report) (string, error) {
+	var b strings.Builder
+	b.WriteString("Graph\n")
+	b.WriteString(fmt.Sprintf("  nodes: %d\n", r.Summary.Nodes))
+	b.WriteString(fmt.Sprintf("  edges: %d\n", r.Summary.Edges))
+	b.WriteString(fmt.Sprintf("  targets: %d\n", r.Summary.Targets))
+	b.WriteString(fmt.Sprintf("  external products: %d\n", r.Summary.ExternalProducts))
+	b.WriteString(fmt.Sprintf("  density: %.4f\n", r.Summary.Density))
+	b.WriteString(fmt.Sprintf("  strongly connected components: %d\n", r.Summary.Components))
+	b.WriteString(fmt.Sprintf("  cycles: %d\n", r.Summary.Cycles))
+	if len(r.Summary.LongestChain) == 0 {
+		b.WriteString("  longest chain: (none)\n")
+	} else {
+		b.WriteString(fmt.Sprintf("  longest chain (%d): %s\n", len(r.Summary.LongestChain)-1, strings.Join(r.Summary.LongestChain, " -> ")))
+	}
+
+	b.WriteString("\nTargets\n")
+	if len(r.Targets) == 0 {
+		b.WriteString("(none)")
+		return b.String(), nil
+	}
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tFAN-IN\tFAN-OUT\tTRANSITIVE-DEPS\tTRANSITIVE-DEPENDENTS\tINSTABILITY\tDEPTH")
+	for _, m := range r.Targets {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.2f\t%d\n",
+			strings.ReplaceAll(m.Label, "\n", " "), m.FanIn, m.FanOut, m.TransitiveDeps, m.TransitiveDependents, m.Instability, m.Depth)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to format metrics table", err)
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// JSON renders a metrics report as indented JSON.
+func JSON(r Report) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to encode metrics report", err)
+	}
+	return string(data), nil
+}
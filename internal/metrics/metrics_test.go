@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func sampleGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":        {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::Feature":    {ID: "target::Feature", Label: "Feature", Kind: graph.NodeKindTarget},
+			"target::Core":       {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"pkg::x::Networking": {ID: "pkg::x::Networking", Label: "Networking", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Feature", Kind: graph.EdgeKindTarget},
+			{FromID: "target::App", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Feature", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::x::Networking", Kind: graph.EdgeKindProduct},
+		},
+	}
+}
+
+func findTarget(t *testing.T, r Report, label string) TargetMetrics {
+	t.Helper()
+	for _, m := range r.Targets {
+		if m.Label == label {
+			return m
+		}
+	}
+	t.Fatalf("missing metrics for %s", label)
+	return TargetMetrics{}
+}
+
+func TestComputeSummary(t *testing.T) {
+	r := Compute(sampleGraph())
+	s := r.Summary
+	if s.Nodes != 4 || s.Edges != 4 || s.Targets != 3 || s.ExternalProducts != 1 {
+		t.Fatalf("unexpected counts: %#v", s)
+	}
+	if s.Density < 0.333 || s.Density > 0.334 {
+		t.Fatalf("unexpected density %f", s.Density)
+	}
+	if s.Components != 4 || s.Cycles != 0 {
+		t.Fatalf("unexpected component counts: %#v", s)
+	}
+	if strings.Join(s.LongestChain, ",") != "App,Feature,Core,Networking" {
+		t.Fatalf("unexpected longest chain: %#v", s.LongestChain)
+	}
+}
+
+func TestComputePerTargetMetrics(t *testing.T) {
+	r := Compute(sampleGraph())
+	if len(r.Targets) != 3 {
+		t.Fatalf("expected only target nodes, got %#v", r.Targets)
+	}
+
+	core := findTarget(t, r, "Core")
+	if core.FanIn != 2 || core.FanOut != 1 {
+		t.Fatalf("unexpected Core coupling: %#v", core)
+	}
+	if core.TransitiveDeps != 1 || core.TransitiveDependents != 2 {
+		t.Fatalf("unexpected Core transitive counts: %#v", core)
+	}
+	if core.Instability < 0.333 || core.Instability > 0.334 {
+		t.Fatalf("unexpected Core instability %f", core.Instability)
+	}
+	if core.Depth != 1 {
+		t.Fatalf("unexpected Core depth %d", core.Depth)
+	}
+
+	app := findTarget(t, r, "App")
+	if app.Instability != 1 || app.Depth != 3 || app.TransitiveDeps != 3 {
+		t.Fatalf("unexpected App metrics: %#v", app)
+	}
+}
+
+func TestComputeIsolatedTargetHasZeroInstability(t *testing.T) {
+	r := Compute(graph.Graph{Nodes: map[string]graph.Node{
+		"target::Solo": {ID: "target::Solo", Label: "Solo", Kind: graph.NodeKindTarget},
+	}})
+	if r.Targets[0].Instability != 0 || r.Summary.Density != 0 {
+		t.Fatalf("unexpected isolated metrics: %#v", r)
+	}
+}
+
+func TestComputeCountsCycles(t *testing.T) {
+	r := Compute(graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::A": {ID: "target::A", Label: "A", Kind: graph.NodeKindTarget},
+			"target::B": {ID: "target::B", Label: "B", Kind: graph.NodeKindTarget},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::A", ToID: "target::B", Kind: graph.EdgeKindTarget},
+			{FromID: "target::B", ToID: "target::A", Kind: graph.EdgeKindTarget},
+		},
+	})
+	if r.Summary.Cycles != 1 || r.Summary.Components != 1 {
+		t.Fatalf("expected one cycle component, got %#v", r.Summary)
+	}
+	a := findTarget(t, r, "A")
+	if a.TransitiveDeps != 1 || a.TransitiveDependents != 1 {
+		t.Fatalf("expected self excluded from transitive counts, got %#v", a)
+	}
+}
+
+func TestTextIncludesSummaryAndTargetTable(t *testing.T) {
+	out, err := Text(Compute(sampleGraph()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		"nodes: 4",
+		"density: 0.3333",
+		"longest chain (3): App -> Feature -> Core -> Networking",
+		"TARGET",
+		"INSTABILITY",
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestJSONRoundTrips(t *testing.T) {
+	out, err := JSON(Compute(sampleGraph()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.Summary.Nodes != 4 || len(decoded.Targets) != 3 {
+		t.Fatalf("unexpected decoded report: %#v", decoded)
+	}
+	for _, key := range []string{`"external_products"`, `"strongly_connected_components"`, `"longest_chain"`, `"fan_in"`, `"fan_out"`, `"transitive_dependencies"`, `"transitive_dependents"`} {
+		if !strings.Contains(out, key) {
+			t.Fatalf("expected snake_case key %s in:\n%s", key, out)
+		}
+	}
+}
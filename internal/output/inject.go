@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+// Markers delimit the generated region of a document updated by Inject.
+const (
+	StartMarker = "<!-- swift-deps-diagram:start -->"
+	EndMarker   = "<!-- swift-deps-diagram:end -->"
+)
+
+// Splice replaces the text between every StartMarker/EndMarker pair in document with
+// block, keeping the markers and everything outside them unchanged.
+func Splice(document, block string) (string, error) {
+	block = strings.TrimRight(block, "\n")
+	newline := "\n"
+	if strings.Contains(document, "\r\n") {
+		newline = "\r\n"
+		block = strings.ReplaceAll(block, "\n", newline)
+	}
+
+	var b strings.Builder
+	rest := document
+	regions := 0
+	for {
+		start := strings.Index(rest, StartMarker)
+		if start < 0 {
+			break
+		}
+		if strings.Contains(rest[:start], EndMarker) {
+			return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s has no matching %s", EndMarker, StartMarker), nil)
+		}
+		afterStart := start + len(StartMarker)
+		end := strings.Index(rest[afterStart:], EndMarker)
+		if end < 0 {
+			return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s has no matching %s", StartMarker, EndMarker), nil)
+		}
+		end += afterStart
+		if nested := strings.Index(rest[afterStart:end], StartMarker); nested >= 0 {
+			return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s appears twice before %s", StartMarker, EndMarker), nil)
+		}
+		b.WriteString(rest[:afterStart])
+		b.WriteString(newline)
+		b.WriteString(block)
+		b.WriteString(newline)
+		b.WriteString(EndMarker)
+		rest = rest[end+len(EndMarker):]
+		regions++
+	}
+	if strings.Contains(rest, EndMarker) {
+		return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s has no matching %s", EndMarker, StartMarker), nil)
+	}
+	if regions == 0 {
+		return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("no %s marker found", StartMarker), nil)
+	}
+	b.WriteString(rest)
+	return b.String(), nil
+}
+
+// Inject splices block into the marked regions of the file at path and reports whether
+// the file content changed. With check set, or when nothing changed, the file is left
+// untouched; otherwise it is rewritten atomically like Write.
+func Inject(block, path string, check bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, apperrors.New(apperrors.KindInputNotFound, fmt.Sprintf("inject target not found at %s", path), err)
+		}
+		return false, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading %s", path), err)
+	}
+	updated, err := Splice(string(data), block)
+	if err != nil {
+		return false, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("cannot inject into %s", path), err)
+	}
+	if updated == string(data) {
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+	if err := Write(updated, path, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+func TestSpliceReplacesEveryMarkedRegion(t *testing.T) {
+	doc := "# Title\n\n" + StartMarker + "\nold\n" + EndMarker + "\n\ntext\n" + StartMarker + EndMarker + "\n"
+	got, err := Splice(doc, "new\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "# Title\n\n" + StartMarker + "\nnew\n" + EndMarker + "\n\ntext\n" + StartMarker + "\nnew\n" + EndMarker + "\n"
+	if got != want {
+		t.Fatalf("unexpected document:\n%s", got)
+	}
+}
+
+func TestSpliceKeepsWindowsLineEndings(t *testing.T) {
+	doc := "a\r\n" + StartMarker + "\r\nold\r\n" + EndMarker + "\r\nb\r\n"
+	got, err := Splice(doc, "x\ny")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "a\r\n" + StartMarker + "\r\nx\r\ny\r\n" + EndMarker + "\r\nb\r\n"; got != want {
+		t.Fatalf("unexpected document %q", got)
+	}
+}
+
+func TestSpliceRejectsMissingOrUnbalancedMarkers(t *testing.T) {
+	for _, doc := range []string{
+		"no markers",
+		StartMarker + "\nunterminated",
+		"stray\n" + EndMarker,
+		StartMarker + "\n" + StartMarker + "\n" + EndMarker,
+		StartMarker + EndMarker + EndMarker,
+		EndMarker + StartMarker + EndMarker,
+	} {
+		if _, err := Splice(doc, "x"); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args for %q, got %v", doc, err)
+		}
+	}
+}
+
+func TestInjectRewritesOnlyStaleFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	original := "intro\n" + StartMarker + "\nold\n" + EndMarker + "\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	changed, err := Inject("new", path, true)
+	if err != nil || !changed {
+		t.Fatalf("expected check to report a change, got %v, %v", changed, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Fatalf("check must not modify the file, got %q", data)
+	}
+
+	changed, err = Inject("new", path, false)
+	if err != nil || !changed {
+		t.Fatalf("expected inject to update the file, got %v, %v", changed, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "intro\n"+StartMarker+"\nnew\n"+EndMarker+"\n" {
+		t.Fatalf("unexpected injected file %q", data)
+	}
+
+	changed, err = Inject("new", path, true)
+	if err != nil || changed {
+		t.Fatalf("expected up-to-date file, got %v, %v", changed, err)
+	}
+}
+
+func TestInjectReportsMissingFile(t *testing.T) {
+	_, err := Inject("x", filepath.Join(t.TempDir(), "missing.md"), false)
+	if !apperrors.IsKind(err, apperrors.KindInputNotFound) {
+		t.Fatalf("expected input not found, got %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package pathquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// maxPaths caps the number of simple paths collected by an --all query.
+const maxPaths = 1000
+
+// maxSteps caps the number of path extensions an --all query explores, so graphs with
+// many long branches cannot stall the search.
+const maxSteps = 1_000_000
+
+// Limit names the bound that stopped an --all query early.
+type Limit string
+
+const (
+	// LimitPaths means the query stopped after collecting maxPaths paths.
+	LimitPaths Limit = "paths"
+	// LimitSteps means the query stopped after exploring maxSteps path extensions.
+	LimitSteps Limit = "steps"
+)
+
+// Result holds the dependency chains connecting two nodes.
+type Result struct {
+	FromID string
+	ToID   string
+	// Paths lists node IDs from FromID to ToID, shortest first.
+	Paths [][]string
+	// TruncatedBy names the limit that stopped an --all query, or is empty when the
+	// search completed.
+	TruncatedBy Limit
+}
+
+// Truncated reports whether an --all query stopped before exploring every path.
+func (r Result) Truncated() bool {
+	return r.TruncatedBy != ""
+}
+
+// ResolveNode finds a node by exact ID, then by unique label.
+func ResolveNode(g graph.Graph, ref string) (string, error) {
+	if _, ok := g.Nodes[ref]; ok {
+		return ref, nil
+	}
+	matches := make([]string, 0)
+	for _, id := range graph.SortedNodeIDs(g) {
+		if g.Nodes[id].Label == ref {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("no node matches %q", ref), nil)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%q matches several nodes, use one of the IDs: %s", ref, strings.Join(matches, ", ")), nil)
+	}
+}
+
+// Find returns the shortest dependency path from fromRef to toRef, or every simple
+// path when all is set. Both references are resolved with ResolveNode. When they name
+// the same node, the paths are the dependency cycles through it.
+func Find(g graph.Graph, fromRef, toRef string, all bool) (Result, error) {
+	fromID, err := ResolveNode(g, fromRef)
+	if err != nil {
+		return Result{}, err
+	}
+	toID, err := ResolveNode(g, toRef)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{FromID: fromID, ToID: toID}
+	succ := graph.Successors(g)
+	if all {
+		result.Paths, result.TruncatedBy = allPaths(succ, reachingNodes(graph.Predecessors(g), toID), fromID, toID)
+	} else if path := shortestPath(succ, fromID, toID); path != nil {
+		result.Paths = [][]string{path}
+	}
+	return result, nil
+}
+
+func shortestPath(succ map[string][]string, fromID, toID string) []string {
+	parent := map[string]string{fromID: ""}
+	queue := []string{fromID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range succ[id] {
+			if next == toID {
+				path := []string{next}
+				for current := id; current != ""; current = parent[current] {
+					path = append([]string{current}, path...)
+				}
+				return path
+			}
+			if _, ok := parent[next]; ok {
+				continue
+			}
+			parent[next] = id
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+// reachingNodes returns the nodes from which toID can be reached, toID included.
+func reachingNodes(pred map[string][]string, toID string) map[string]bool {
+	reaching := map[string]bool{toID: true}
+	queue := []string{toID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, prev := range pred[id] {
+			if !reaching[prev] {
+				reaching[prev] = true
+				queue = append(queue, prev)
+			}
+		}
+	}
+	return reaching
+}
+
+// allPaths walks the simple paths from fromID that stay within reaching, the nodes
+// that can still reach toID, so branches that cannot lead to toID are never explored.
+func allPaths(succ map[string][]string, reaching map[string]bool, fromID, toID string) ([][]string, Limit) {
+	paths := make([][]string, 0)
+	var truncated Limit
+	steps := 0
+	onPath := map[string]bool{fromID: true}
+	path := []string{fromID}
+
+	var walk func(string)
+	walk = func(id string) {
+		for _, next := range succ[id] {
+			if truncated != "" {
+				return
+			}
+			if !reaching[next] {
+				continue
+			}
+			if steps++; steps > maxSteps {
+				truncated = LimitSteps
+				return
+			}
+			if next == toID {
+				if len(paths) == maxPaths {
+					truncated = LimitPaths
+					return
+				}
+				found := append(append([]string(nil), path...), next)
+				paths = append(paths, found)
+				continue
+			}
+			if onPath[next] {
+				continue
+			}
+			onPath[next] = true
+			path = append(path, next)
+			walk(next)
+			path = path[:len(path)-1]
+			onPath[next] = false
+		}
+	}
+	if reaching[fromID] {
+		walk(fromID)
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		if len(paths[i]) != len(paths[j]) {
+			return len(paths[i]) < len(paths[j])
+		}
+		return strings.Join(paths[i], "\x00") < strings.Join(paths[j], "\x00")
+	})
+	return paths, truncated
+}
+
+// Subgraph returns the nodes and edges of g that lie on the result's paths.
+func (r Result) Subgraph(g graph.Graph) graph.Graph {
+	nodes := make(map[string]graph.Node)
+	steps := make(map[[2]string]struct{})
+	for _, path := range r.Paths {
+		for i, id := range path {
+			nodes[id] = g.Nodes[id]
+			if i > 0 {
+				steps[[2]string{path[i-1], id}] = struct{}{}
+			}
+		}
+	}
+
+	edges := make([]graph.Edge, 0)
+	for _, edge := range g.Edges {
+		if _, ok := steps[[2]string{edge.FromID, edge.ToID}]; ok {
+			edges = append(edges, edge)
+		}
+	}
+	sub := graph.Graph{Nodes: nodes, Edges: edges}
+	sub.Edges = graph.SortedEdges(sub)
+	return sub
+}
+
+// NoPathMessage describes a result without paths.
+func NoPathMessage(g graph.Graph, r Result) string {
+	message := fmt.Sprintf("no dependency path from %s to %s", g.Nodes[r.FromID].Label, g.Nodes[r.ToID].Label)
+	if r.Truncated() {
+		message += " found before the search " + truncationNote(r.TruncatedBy)
+	}
+	return message
+}
+
+// truncationNote describes the limit that stopped an --all query.
+func truncationNote(limit Limit) string {
+	if limit == LimitSteps {
+		return fmt.Sprintf("stopped after exploring %d steps", maxSteps)
+	}
+	return fmt.Sprintf("stopped after %d paths", maxPaths)
+}
+
+// NodeIDs returns the sorted IDs of every node on the result's paths.
+func (r Result) NodeIDs() []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0)
+	for _, path := range r.Paths {
+		for _, id := range path {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Text renders each path on its own line as "A -> B -> C". Callers report results
+// without paths through NoPathMessage instead.
+func Text(g graph.Graph, r Result) string {
+	lines := make([]string, 0, len(r.Paths)+1)
+	for _, path := range r.Paths {
+		labels := make([]string, 0, len(path))
+		for _, id := range path {
+			labels = append(labels, strings.ReplaceAll(g.Nodes[id].Label, "\n", " "))
+		}
+		lines = append(lines, strings.Join(labels, " -> "))
+	}
+	if r.Truncated() {
+		lines = append(lines, "("+truncationNote(r.TruncatedBy)+")")
+	}
+	return strings.Join(lines, "\n")
+}
+
+type jsonNode struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type jsonResult struct {
+	From        jsonNode     `json:"from"`
+	To          jsonNode     `json:"to"`
+	Paths       [][]jsonNode `json:"paths"`
+	Truncated   bool         `json:"truncated"`
+	TruncatedBy Limit        `json:"truncated_by,omitempty"`
+}
+
+// JSON renders the result with node IDs and labels for every path step.
+func JSON(g graph.Graph, r Result) (string, error) {
+	toJSONNode := func(id string) jsonNode {
+		return jsonNode{ID: id, Label: g.Nodes[id].Label}
+	}
+	out := jsonResult{
+		From:        toJSONNode(r.FromID),
+		To:          toJSONNode(r.ToID),
+		Paths:       make([][]jsonNode, 0, len(r.Paths)),
+		Truncated:   r.Truncated(),
+		TruncatedBy: r.TruncatedBy,
+	}
+	for _, path := range r.Paths {
+		steps := make([]jsonNode, 0, len(path))
+		for _, id := range path {
+			steps = append(steps, toJSONNode(id))
+		}
+		out.Paths = append(out.Paths, steps)
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to encode path query result", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,245 @@
+package pathquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func sampleGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::Widget":     {ID: "target::Widget", Label: "Widget", Kind: graph.NodeKindTarget},
+			"target::Feature":    {ID: "target::Feature", Label: "Feature", Kind: graph.NodeKindTarget},
+			"target::Core":       {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"target::Tool":       {ID: "target::Tool", Label: "Tool", Kind: graph.NodeKindTarget},
+			"pkg::x::Networking": {ID: "pkg::x::Networking", Label: "Networking", Kind: graph.NodeKindExternalProduct},
+			"pkg::y::Networking": {ID: "pkg::y::Networking", Label: "Networking", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::Widget", ToID: "target::Feature", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Widget", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Feature", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Feature", ToID: "target::Widget", Kind: graph.EdgeKindByName},
+			{FromID: "target::Core", ToID: "pkg::x::Networking", Kind: graph.EdgeKindProduct},
+		},
+	}
+}
+
+func TestFindShortestPath(t *testing.T) {
+	r, err := Find(sampleGraph(), "Widget", "pkg::x::Networking", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 1 || strings.Join(r.Paths[0], ",") != "target::Widget,target::Core,pkg::x::Networking" {
+		t.Fatalf("unexpected shortest path: %#v", r.Paths)
+	}
+}
+
+func TestFindAllPathsSkipsCycles(t *testing.T) {
+	r, err := Find(sampleGraph(), "Widget", "pkg::x::Networking", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 2 {
+		t.Fatalf("expected 2 paths, got %#v", r.Paths)
+	}
+	if len(r.Paths[0]) != 3 || len(r.Paths[1]) != 4 {
+		t.Fatalf("expected shortest path first, got %#v", r.Paths)
+	}
+	if r.Truncated() {
+		t.Fatal("did not expect truncation")
+	}
+}
+
+// layeredGraph returns a DAG of width-node layers in which every node depends on every
+// node of the next layer.
+func layeredGraph(layers, width int) graph.Graph {
+	g := graph.Graph{Nodes: map[string]graph.Node{}}
+	id := func(layer, i int) string { return fmt.Sprintf("target::L%02dN%d", layer, i) }
+	for layer := 0; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			g.Nodes[id(layer, i)] = graph.Node{ID: id(layer, i), Label: fmt.Sprintf("L%02dN%d", layer, i), Kind: graph.NodeKindTarget}
+			if layer == 0 {
+				continue
+			}
+			for j := 0; j < width; j++ {
+				g.Edges = append(g.Edges, graph.Edge{FromID: id(layer-1, j), ToID: id(layer, i), Kind: graph.EdgeKindTarget})
+			}
+		}
+	}
+	return g
+}
+
+func TestFindAllPathsBoundsWideGraphs(t *testing.T) {
+	g := layeredGraph(40, 3)
+	g.Nodes["target::Island"] = graph.Node{ID: "target::Island", Label: "Island", Kind: graph.NodeKindTarget}
+	g.Nodes["target::Top"] = graph.Node{ID: "target::Top", Label: "Top", Kind: graph.NodeKindTarget}
+	for i := 0; i < 3; i++ {
+		g.Edges = append(g.Edges, graph.Edge{FromID: "target::Top", ToID: fmt.Sprintf("target::L00N%d", i), Kind: graph.EdgeKindTarget})
+	}
+	g.Edges = append(g.Edges, graph.Edge{FromID: "target::Top", ToID: "target::L01N0", Kind: graph.EdgeKindTarget})
+
+	start := time.Now()
+	r, err := Find(g, "Top", "Island", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 0 || r.Truncated() {
+		t.Fatalf("expected no paths to an unreachable node, got %#v", r)
+	}
+
+	r, err = Find(g, "Top", "L01N0", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 4 || r.Truncated() {
+		t.Fatalf("expected only the paths into the second layer, got %d (truncated by %q)", len(r.Paths), r.TruncatedBy)
+	}
+
+	r, err = Find(g, "Top", "L39N0", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != maxPaths || r.TruncatedBy != LimitPaths {
+		t.Fatalf("expected a truncated search, got %d paths (truncated by %q)", len(r.Paths), r.TruncatedBy)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected bounded --all searches, took %s", elapsed)
+	}
+}
+
+func TestFindReportsNoPath(t *testing.T) {
+	g := sampleGraph()
+	r, err := Find(g, "Tool", "Core", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 0 {
+		t.Fatalf("expected no paths, got %#v", r.Paths)
+	}
+	if out := NoPathMessage(g, r); out != "no dependency path from Tool to Core" {
+		t.Fatalf("unexpected no-path message %q", out)
+	}
+}
+
+func TestFindAllPathsReportsStepLimit(t *testing.T) {
+	// Every clique node reaches Target only back through Start, which is already on the
+	// path, so the search explores the clique's simple paths without finding new ones.
+	g := graph.Graph{Nodes: map[string]graph.Node{}}
+	add := func(id string) {
+		g.Nodes["target::"+id] = graph.Node{ID: "target::" + id, Label: id, Kind: graph.NodeKindTarget}
+	}
+	link := func(from, to string) {
+		g.Edges = append(g.Edges, graph.Edge{FromID: "target::" + from, ToID: "target::" + to, Kind: graph.EdgeKindTarget})
+	}
+	add("Start")
+	add("Target")
+	link("Start", "Target")
+	for i := 0; i < 10; i++ {
+		node := fmt.Sprintf("n%d", i)
+		add(node)
+		link("Start", node)
+		link(node, "Start")
+		for j := 0; j < 10; j++ {
+			if i != j {
+				link(node, fmt.Sprintf("n%d", j))
+			}
+		}
+	}
+
+	r, err := Find(g, "Start", "Target", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 1 || r.TruncatedBy != LimitSteps {
+		t.Fatalf("expected one path and a step-limited search, got %d paths (truncated by %q)", len(r.Paths), r.TruncatedBy)
+	}
+	if out := Text(g, r); out != "Start -> Target\n(stopped after exploring 1000000 steps)" {
+		t.Fatalf("unexpected text:\n%s", out)
+	}
+	out, err := JSON(g, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"truncated": true`) || !strings.Contains(out, `"truncated_by": "steps"`) {
+		t.Fatalf("expected the step limit in JSON:\n%s", out)
+	}
+}
+
+func TestFindSameEndpointsReturnsCycles(t *testing.T) {
+	g := sampleGraph()
+	r, err := Find(g, "Widget", "Widget", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 1 || strings.Join(r.Paths[0], ",") != "target::Widget,target::Feature,target::Widget" {
+		t.Fatalf("expected the cycle through Widget, got %#v", r.Paths)
+	}
+	if ids := strings.Join(r.NodeIDs(), ","); ids != "target::Feature,target::Widget" {
+		t.Fatalf("unexpected path nodes %s", ids)
+	}
+
+	r, err = Find(g, "Core", "Core", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Paths) != 0 {
+		t.Fatalf("expected no cycle through Core, got %#v", r.Paths)
+	}
+}
+
+func TestResolveNodeErrors(t *testing.T) {
+	_, err := ResolveNode(sampleGraph(), "Missing")
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args for unknown node, got %v", err)
+	}
+	_, err = ResolveNode(sampleGraph(), "Networking")
+	if !apperrors.IsKind(err, apperrors.KindInvalidArgs) || !strings.Contains(err.Error(), "pkg::x::Networking, pkg::y::Networking") {
+		t.Fatalf("expected ambiguous label error listing IDs, got %v", err)
+	}
+}
+
+func TestSubgraphKeepsOnlyPathElements(t *testing.T) {
+	g := sampleGraph()
+	r, err := Find(g, "Widget", "pkg::x::Networking", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sub := r.Subgraph(g)
+	if len(sub.Nodes) != 3 || len(sub.Edges) != 2 {
+		t.Fatalf("unexpected subgraph: %#v", sub)
+	}
+	if _, ok := sub.Nodes["target::Feature"]; ok {
+		t.Fatal("did not expect Feature in shortest path subgraph")
+	}
+}
+
+func TestTextAndJSON(t *testing.T) {
+	g := sampleGraph()
+	r, err := Find(g, "Widget", "pkg::x::Networking", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Widget -> Core -> Networking\nWidget -> Feature -> Core -> Networking"
+	if out := Text(g, r); out != expected {
+		t.Fatalf("unexpected text:\n%s", out)
+	}
+
+	out, err := JSON(g, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded jsonResult
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded.From.Label != "Widget" || len(decoded.Paths) != 2 || decoded.Paths[0][1].ID != "target::Core" {
+		t.Fatalf("unexpected JSON result: %#v", decoded)
+	}
+}
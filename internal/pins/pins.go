@@ -0,0 +1,144 @@
+package pins
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+// FileName is the SwiftPM lockfile that records resolved package versions.
+const FileName = "Package.resolved"
+
+type state struct {
+	Version  string `json:"version"`
+	Branch   string `json:"branch"`
+	Revision string `json:"revision"`
+}
+
+type pin struct {
+	// Identity and Location are set by format versions 2 and 3.
+	Identity string `json:"identity"`
+	Location string `json:"location"`
+	// Package and RepositoryURL are set by format version 1.
+	Package       string `json:"package"`
+	RepositoryURL string `json:"repositoryURL"`
+	State         state  `json:"state"`
+}
+
+type resolvedFile struct {
+	Pins   []pin `json:"pins"`
+	Object struct {
+		Pins []pin `json:"pins"`
+	} `json:"object"`
+}
+
+// describe returns the pinned version, falling back to the branch or a short revision.
+func (s state) describe() string {
+	switch {
+	case s.Version != "":
+		return s.Version
+	case s.Branch != "":
+		return "branch " + s.Branch
+	case len(s.Revision) > 7:
+		return s.Revision[:7]
+	default:
+		return s.Revision
+	}
+}
+
+func identityFromLocation(location string) string {
+	if location == "" {
+		return ""
+	}
+	if u, err := url.Parse(location); err == nil && u.Path != "" {
+		location = u.Path
+	}
+	return strings.TrimSuffix(path.Base(location), ".git")
+}
+
+// Key normalizes a package identity for version lookups.
+func Key(identity string) string {
+	return strings.ToLower(identity)
+}
+
+// Load reads a Package.resolved file in any format version and returns the pinned
+// versions keyed by Key(package identity).
+func Load(resolvedPath string) (map[string]string, error) {
+	data, err := os.ReadFile(resolvedPath)
+	if err != nil {
+		return nil, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading %s", resolvedPath), err)
+	}
+	var file resolvedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed to decode %s", resolvedPath), err)
+	}
+
+	versions := make(map[string]string)
+	for _, p := range append(file.Pins, file.Object.Pins...) {
+		version := p.State.describe()
+		if version == "" {
+			continue
+		}
+		for _, identity := range []string{p.Identity, identityFromLocation(p.Location), identityFromLocation(p.RepositoryURL), p.Package} {
+			if identity != "" {
+				versions[Key(identity)] = version
+			}
+		}
+	}
+	return versions, nil
+}
+
+// Candidates lists the Package.resolved locations SwiftPM and Xcode use for an input:
+// next to Package.swift in root, and inside the shared data of the given or discovered
+// workspaces and projects.
+func Candidates(root, projectPath, workspacePath string) []string {
+	candidates := []string{filepath.Join(root, FileName)}
+	workspaces := []string{}
+	projects := []string{}
+	if workspacePath != "" {
+		workspaces = append(workspaces, workspacePath)
+	}
+	if projectPath != "" {
+		projects = append(projects, projectPath)
+	}
+	if workspacePath == "" && projectPath == "" {
+		found, _ := filepath.Glob(filepath.Join(root, "*.xcworkspace"))
+		workspaces = append(workspaces, found...)
+		found, _ = filepath.Glob(filepath.Join(root, "*.xcodeproj"))
+		projects = append(projects, found...)
+	}
+	for _, workspace := range workspaces {
+		candidates = append(candidates, filepath.Join(workspace, "xcshareddata", "swiftpm", FileName))
+	}
+	for _, project := range projects {
+		candidates = append(candidates, filepath.Join(project, "project.xcworkspace", "xcshareddata", "swiftpm", FileName))
+	}
+	return candidates
+}
+
+// Find loads every existing Package.resolved candidate for the input and merges their
+// versions. Inputs without a lockfile yield an empty map.
+func Find(root, projectPath, workspacePath string) (map[string]string, error) {
+	versions := make(map[string]string)
+	for _, candidate := range Candidates(root, projectPath, workspacePath) {
+		if _, err := os.Stat(candidate); err != nil {
+			continue
+		}
+		loaded, err := Load(candidate)
+		if err != nil {
+			return nil, err
+		}
+		for identity, version := range loaded {
+			if _, ok := versions[identity]; !ok {
+				versions[identity] = version
+			}
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,86 @@
+package pins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestLoadReadsVersion2Pins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	writeFile(t, path, `{
+  "pins": [
+    {"identity": "swift-nio", "kind": "remoteSourceControl", "location": "https://github.com/apple/swift-nio.git", "state": {"revision": "abc", "version": "2.62.0"}},
+    {"identity": "swift-log", "kind": "remoteSourceControl", "location": "https://github.com/apple/swift-log", "state": {"branch": "main", "revision": "0123456789"}},
+    {"identity": "Pinned", "kind": "remoteSourceControl", "location": "https://example.com/pinned.git", "state": {"revision": "0123456789abcdef"}}
+  ],
+  "version": 2
+}`)
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["swift-nio"] != "2.62.0" || got["swift-log"] != "branch main" || got["pinned"] != "0123456" {
+		t.Fatalf("unexpected versions: %#v", got)
+	}
+}
+
+func TestLoadReadsVersion1Pins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	writeFile(t, path, `{
+  "object": {
+    "pins": [
+      {"package": "Alamofire", "repositoryURL": "https://github.com/Alamofire/Alamofire.git", "state": {"branch": null, "revision": "abc", "version": "5.8.1"}}
+    ]
+  },
+  "version": 1
+}`)
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["alamofire"] != "5.8.1" {
+		t.Fatalf("unexpected versions: %#v", got)
+	}
+}
+
+func TestLoadRejectsMalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	writeFile(t, path, `{`)
+	if _, err := Load(path); !apperrors.IsKind(err, apperrors.KindRuntime) {
+		t.Fatalf("expected runtime kind, got %v", err)
+	}
+}
+
+func TestFindMergesPackageAndXcodeLockfiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, FileName), `{"pins": [{"identity": "swift-nio", "location": "https://github.com/apple/swift-nio.git", "state": {"version": "2.62.0"}}], "version": 2}`)
+	writeFile(t, filepath.Join(root, "App.xcodeproj", "project.xcworkspace", "xcshareddata", "swiftpm", FileName), `{"pins": [{"identity": "swift-nio", "location": "https://github.com/apple/swift-nio.git", "state": {"version": "1.0.0"}}, {"identity": "kingfisher", "location": "https://github.com/onevcat/Kingfisher.git", "state": {"version": "7.10.0"}}], "version": 2}`)
+
+	got, err := Find(root, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["swift-nio"] != "2.62.0" || got["kingfisher"] != "7.10.0" {
+		t.Fatalf("unexpected versions: %#v", got)
+	}
+
+	empty, err := Find(t.TempDir(), "", "")
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("expected no versions without lockfiles, got %#v, %v", empty, err)
+	}
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// quoteD2 returns a double-quoted D2 string. "$" is escaped so labels are never
+// treated as variable substitutions.
+func quoteD2(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "$", "\\$")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return "\"" + s + "\""
+}
+
+// d2TypeShapes holds the shape of target types that are not drawn as rectangles.
+var d2TypeShapes = map[graph.TargetType]string{
+	graph.TargetTypeApp:           "hexagon",
+	graph.TargetTypeAppExtension:  "step",
+	graph.TargetTypeFramework:     "package",
+	graph.TargetTypeExecutable:    "parallelogram",
+	graph.TargetTypeTest:          "page",
+	graph.TargetTypeUITest:        "page",
+	graph.TargetTypeMacro:         "diamond",
+	graph.TargetTypePlugin:        "queue",
+	graph.TargetTypeBinary:        "stored_data",
+	graph.TargetTypeSystemLibrary: "cylinder",
+	graph.TargetTypeAggregate:     "circle",
+}
+
+func d2NodeStyle(node graph.Node) string {
+	if node.Kind == graph.NodeKindExternalProduct {
+		return "shape: oval; style.stroke-dash: 3"
+	}
+	style, ok := targetStyle(node)
+	if !ok {
+		return "shape: rectangle"
+	}
+	shape, ok := d2TypeShapes[node.Type]
+	if !ok {
+		shape = "rectangle"
+	}
+	return fmt.Sprintf("shape: %s; style.fill: %s; style.stroke: %s", shape, quoteD2(style.fill), quoteD2(style.stroke))
+}
+
+func d2EdgeStyle(kind graph.EdgeKind) string {
+	switch kind {
+	case graph.EdgeKindProduct:
+		return "style.stroke-dash: 3"
+	case graph.EdgeKindByName:
+		return "style.stroke-dash: 1; style.opacity: 0.6"
+	default:
+		return ""
+	}
+}
+
+// D2Options customize D2 rendering.
+type D2Options struct {
+	// Groups maps node IDs to container names.
+	Groups map[string]string
+}
+
+// D2 renders a dependency graph as D2 source.
+func D2(g graph.Graph) (string, error) {
+	return D2WithOptions(g, D2Options{})
+}
+
+// D2WithOptions renders a dependency graph as D2 source using opts. Grouped nodes are
+// placed in containers and referenced by their container path in edges.
+func D2WithOptions(g graph.Graph, opts D2Options) (string, error) {
+	idList := graph.SortedNodeIDs(g)
+	keys := make(map[string]string, len(idList))
+	for i, id := range idList {
+		keys[id] = fmt.Sprintf("n%d", i+1)
+	}
+
+	var b strings.Builder
+	b.WriteString("direction: down\n")
+
+	writeNode := func(id, indent string) error {
+		node, ok := g.Nodes[id]
+		if !ok {
+			return apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		b.WriteString(fmt.Sprintf("%s%s: %s {%s}\n", indent, keys[id], quoteD2(node.Label), d2NodeStyle(node)))
+		return nil
+	}
+
+	groups, ungrouped := partitionNodes(g, opts.Groups)
+	paths := make(map[string]string, len(idList))
+	for i, group := range groups {
+		container := fmt.Sprintf("c%d", i+1)
+		b.WriteString(fmt.Sprintf("%s: %s {\n", container, quoteD2(group.Name)))
+		for _, id := range group.IDs {
+			if err := writeNode(id, "  "); err != nil {
+				return "", err
+			}
+			paths[id] = container + "." + keys[id]
+		}
+		b.WriteString("}\n")
+	}
+	for _, id := range ungrouped {
+		if err := writeNode(id, ""); err != nil {
+			return "", err
+		}
+		paths[id] = keys[id]
+	}
+
+	for _, edge := range graph.SortedEdges(g) {
+		fromPath, ok := paths[edge.FromID]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown from node", nil)
+		}
+		toPath, ok := paths[edge.ToID]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
+		}
+		line := fmt.Sprintf("%s -> %s", fromPath, toPath)
+		if edge.Weight > 1 {
+			line += ": " + quoteD2(fmt.Sprint(edge.Weight))
+		}
+		if style := d2EdgeStyle(edge.Kind); style != "" {
+			if edge.Weight <= 1 {
+				line += ":"
+			}
+			line += " {" + style + "}"
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,96 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func TestD2RendersShapesAndEdgeStyles(t *testing.T) {
+	out, err := D2(sampleGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := strings.Join([]string{
+		"direction: down",
+		`n1: "ExternalLib" {shape: oval; style.stroke-dash: 3}`,
+		`n2: "App" {shape: rectangle}`,
+		`n3: "Core" {shape: rectangle}`,
+		"n2 -> n1: {style.stroke-dash: 3}",
+		"n2 -> n3",
+	}, "\n")
+	if out != want {
+		t.Fatalf("unexpected D2 output:\n%s", out)
+	}
+}
+
+func TestD2LabelsWeightedAndByNameEdges(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::A": {ID: "target::A", Label: "A", Kind: graph.NodeKindTarget},
+			"name::B":   {ID: "name::B", Label: "B", Kind: graph.NodeKindExternalProduct},
+			"pkg::nio":  {ID: "pkg::nio", Label: "nio", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::A", ToID: "name::B", Kind: graph.EdgeKindByName},
+			{FromID: "target::A", ToID: "pkg::nio", Kind: graph.EdgeKindProduct, Weight: 4},
+		},
+	}
+	out, err := D2(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		"n3 -> n1: {style.stroke-dash: 1; style.opacity: 0.6}",
+		`n3 -> n2: "4" {style.stroke-dash: 3}`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestD2QuotesLabels(t *testing.T) {
+	if got := quoteD2("a\"b\\c ${x}\nd"); got != `"a\"b\\c \${x} d"` {
+		t.Fatalf("unexpected quoting: %s", got)
+	}
+}
+
+func TestD2WithOptionsUsesContainers(t *testing.T) {
+	out, err := D2WithOptions(sampleGraph(), D2Options{Groups: map[string]string{"target::App": "Sample", "target::Core": "Sample"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		"c1: \"Sample\" {\n  n2: \"App\" {shape: rectangle}\n  n3: \"Core\" {shape: rectangle}\n}\n",
+		"c1.n2 -> n1: {style.stroke-dash: 3}",
+		"c1.n2 -> c1.n3",
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestD2DrawsTargetTypes(t *testing.T) {
+	g := sampleGraph()
+	app := g.Nodes["target::App"]
+	app.Type = graph.TargetTypeApp
+	g.Nodes["target::App"] = app
+	core := g.Nodes["target::Core"]
+	core.Type = graph.TargetTypeLibrary
+	g.Nodes["target::Core"] = core
+	out, err := D2(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`n2: "App" {shape: hexagon; style.fill: "#dcf2e3"; style.stroke: "#2da44e"}`,
+		`n3: "Core" {shape: rectangle}`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
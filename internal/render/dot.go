@@ -2,10 +2,12 @@ package render
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	apperrors "swift-deps-diagram/internal/errors"
 	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/theme"
 )
 
 func quoteDOT(s string) string {
@@ -15,29 +17,102 @@ func quoteDOT(s string) string {
 	return "\"" + s + "\""
 }
 
-func dotStyle(kind graph.NodeKind) string {
-	switch kind {
-	case graph.NodeKindTarget:
-		return "shape=box"
-	case graph.NodeKindExternalProduct:
-		return "shape=ellipse,style=dashed"
-	default:
-		return "shape=box"
+func idSet(ids []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
 	}
+	return set
 }
 
+var dotPlainID = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*|-?(\.[0-9]+|[0-9]+(\.[0-9]*)?))$`)
+
+// dotValue leaves identifiers and numerals bare and quotes everything else.
+func dotValue(s string) string {
+	if dotPlainID.MatchString(s) {
+		return s
+	}
+	return quoteDOT(s)
+}
+
+// dotAttributes formats attrs as comma-separated key=value pairs sorted by key.
+func dotAttributes(attrs theme.Attributes) string {
+	parts := make([]string, 0, len(attrs))
+	for _, key := range attrs.Keys() {
+		parts = append(parts, key+"="+dotValue(attrs[key]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// DotOptions customize DOT rendering.
+type DotOptions struct {
+	// Highlight lists node IDs drawn with emphasis.
+	Highlight []string
+	// Groups maps node IDs to cluster names; grouped nodes are drawn inside a
+	// "cluster_N" subgraph per name.
+	Groups map[string]string
+	// Theme supplies graph, node, and edge attributes; nil means theme.Default().
+	Theme *theme.Theme
+}
+
+const dotHighlightStyle = `penwidth=2,color="#d62728"`
+
 // Dot renders a dependency graph in Graphviz DOT format.
 func Dot(g graph.Graph) (string, error) {
+	return DotWithOptions(g, DotOptions{})
+}
+
+// DotWithOptions renders a dependency graph in Graphviz DOT format using opts.
+func DotWithOptions(g graph.Graph, opts DotOptions) (string, error) {
+	highlight := idSet(opts.Highlight)
+	t := theme.Default()
+	if opts.Theme != nil {
+		t = *opts.Theme
+	}
+
 	var b strings.Builder
 	b.WriteString("digraph dependencies {\n")
-	b.WriteString("  rankdir=TB;\n")
+	for _, key := range t.Graph.Keys() {
+		b.WriteString(fmt.Sprintf("  %s=%s;\n", key, dotValue(t.Graph[key])))
+	}
+	if len(t.Node) > 0 {
+		b.WriteString(fmt.Sprintf("  node [%s];\n", dotAttributes(t.Node)))
+	}
+	if len(t.Edge) > 0 {
+		b.WriteString(fmt.Sprintf("  edge [%s];\n", dotAttributes(t.Edge)))
+	}
 
-	for _, id := range graph.SortedNodeIDs(g) {
+	writeNode := func(id, indent string) error {
 		node, ok := g.Nodes[id]
 		if !ok {
-			return "", apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+			return apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		attrs := "label=" + quoteDOT(node.Label)
+		if style := dotAttributes(t.NodeAttributes(node)); style != "" {
+			attrs += "," + style
+		}
+		if _, ok := highlight[id]; ok {
+			attrs += "," + dotHighlightStyle
+		}
+		b.WriteString(fmt.Sprintf("%s%s [%s];\n", indent, quoteDOT(node.ID), attrs))
+		return nil
+	}
+
+	groups, ungrouped := partitionNodes(g, opts.Groups)
+	for i, group := range groups {
+		b.WriteString(fmt.Sprintf("  subgraph %s {\n", quoteDOT(fmt.Sprintf("cluster_%d", i+1))))
+		b.WriteString(fmt.Sprintf("    label=%s;\n", quoteDOT(group.Name)))
+		for _, id := range group.IDs {
+			if err := writeNode(id, "    "); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString("  }\n")
+	}
+	for _, id := range ungrouped {
+		if err := writeNode(id, "  "); err != nil {
+			return "", err
 		}
-		b.WriteString(fmt.Sprintf("  %s [label=%s,%s];\n", quoteDOT(node.ID), quoteDOT(node.Label), dotStyle(node.Kind)))
 	}
 
 	for _, edge := range graph.SortedEdges(g) {
@@ -47,6 +122,17 @@ func Dot(g graph.Graph) (string, error) {
 		if _, ok := g.Nodes[edge.ToID]; !ok {
 			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
 		}
+		attrs := make([]string, 0, 2)
+		if edge.Weight > 1 {
+			attrs = append(attrs, "label="+quoteDOT(fmt.Sprint(edge.Weight)))
+		}
+		if style := dotAttributes(t.EdgeAttributes(edge.Kind)); style != "" {
+			attrs = append(attrs, style)
+		}
+		if len(attrs) > 0 {
+			b.WriteString(fmt.Sprintf("  %s -> %s [%s];\n", quoteDOT(edge.FromID), quoteDOT(edge.ToID), strings.Join(attrs, ",")))
+			continue
+		}
 		b.WriteString(fmt.Sprintf("  %s -> %s;\n", quoteDOT(edge.FromID), quoteDOT(edge.ToID)))
 	}
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/theme"
 )
 
 func TestDotIncludesGraphHeaderAndFooter(t *testing.T) {
@@ -79,3 +80,70 @@ func TestDotDeterministicOutput(t *testing.T) {
 		t.Fatalf("expected deterministic output")
 	}
 }
+
+func TestDotWithOptionsHighlightsNodes(t *testing.T) {
+	out, err := DotWithOptions(sampleGraph(), DotOptions{Highlight: []string{"target::App"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"target::App" [label="App",shape=box,penwidth=2,color="#d62728"];`) {
+		t.Fatalf("expected highlighted App node, got %q", out)
+	}
+	if strings.Contains(out, `"target::Core" [label="Core",shape=box,penwidth`) {
+		t.Fatalf("did not expect Core highlighted, got %q", out)
+	}
+}
+
+func TestDotLabelsWeightedEdges(t *testing.T) {
+	g := sampleGraph()
+	g.Edges[1].Weight = 3
+	out, err := Dot(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, `"target::App" -> "pkg::x::ExternalLib" [label="3"];`) {
+		t.Fatalf("expected weighted edge label, got %q", out)
+	}
+	if !strings.Contains(out, `"target::App" -> "target::Core";`) {
+		t.Fatalf("expected unlabeled single edge, got %q", out)
+	}
+}
+
+func TestDotWithOptionsDrawsClusters(t *testing.T) {
+	out, err := DotWithOptions(sampleGraph(), DotOptions{Groups: map[string]string{"target::App": "Features"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "  subgraph \"cluster_1\" {\n    label=\"Features\";\n    \"target::App\" [label=\"App\"") {
+		t.Fatalf("expected App inside cluster, got %q", out)
+	}
+	if !strings.Contains(out, "\n  \"target::Core\" [label=\"Core\"") {
+		t.Fatalf("expected ungrouped Core at top level, got %q", out)
+	}
+}
+
+func TestDotWithOptionsAppliesTheme(t *testing.T) {
+	g := sampleGraph()
+	g.Edges = append(g.Edges, graph.Edge{FromID: "target::Core", ToID: "pkg::x::ExternalLib", Kind: graph.EdgeKindByName, Weight: 2})
+	dark, _ := theme.Preset("dark")
+	dark.Labels = append(dark.Labels, theme.LabelRule{Pattern: "^Core$", Attributes: theme.Attributes{"penwidth": "2"}})
+
+	out, err := DotWithOptions(g, DotOptions{Theme: &dark})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"  bgcolor=\"#0d1117\";\n",
+		"  rankdir=TB;\n",
+		"  ranksep=0.6;\n",
+		"  node [color=\"#58a6ff\",fontcolor=\"#c9d1d9\",fontname=Helvetica,fontsize=11];\n",
+		"  \"target::Core\" [label=\"Core\",fillcolor=\"#161b22\",penwidth=2,shape=box,style=\"rounded,filled\"];\n",
+		"  \"target::App\" -> \"pkg::x::ExternalLib\" [color=\"#6e7681\",style=dashed];\n",
+		"  \"target::App\" -> \"target::Core\";\n",
+		"  \"target::Core\" -> \"pkg::x::ExternalLib\" [label=\"2\",style=dotted];\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in themed output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+type gexfAttribute struct {
+	ID      string `xml:"id,attr"`
+	Title   string `xml:"title,attr"`
+	Type    string `xml:"type,attr"`
+	Default string `xml:"default,omitempty"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	ID     string      `xml:"id,attr"`
+	Label  string      `xml:"label,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Weight int         `xml:"weight,attr"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Creator string    `xml:"meta>creator"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+// gexfAttributeClasses converts the GraphML keys to GEXF attribute declarations. Labels
+// and weights are native GEXF node and edge fields and are not declared.
+func gexfAttributeClasses() []gexfAttributes {
+	types := map[string]string{"string": "string", "boolean": "boolean", "int": "integer"}
+	nodeAttrs := gexfAttributes{Class: "node"}
+	edgeAttrs := gexfAttributes{Class: "edge"}
+	for _, key := range graphMLKeys {
+		if key.ID == "label" || key.ID == "weight" {
+			continue
+		}
+		attr := gexfAttribute{ID: key.ID, Title: key.Name, Type: types[key.Type], Default: key.Default}
+		if key.For == "edge" {
+			edgeAttrs.Attributes = append(edgeAttrs.Attributes, attr)
+			continue
+		}
+		nodeAttrs.Attributes = append(nodeAttrs.Attributes, attr)
+	}
+	return []gexfAttributes{nodeAttrs, edgeAttrs}
+}
+
+// GEXF renders a dependency graph as GEXF 1.3 with typed node and edge attributes.
+func GEXF(g graph.Graph) (string, error) {
+	doc := gexfDocument{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Creator: "swift-deps-diagram",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes:      gexfAttributeClasses(),
+		},
+	}
+
+	for _, id := range graph.SortedNodeIDs(g) {
+		node, ok := g.Nodes[id]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		out := gexfNode{ID: node.ID, Label: node.Label}
+		for _, attr := range nodeAttributes(node) {
+			if attr[0] == "label" {
+				continue
+			}
+			out.Values = append(out.Values, gexfValue{For: attr[0], Value: attr[1]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, out)
+	}
+
+	for i, edge := range graph.SortedEdges(g) {
+		if _, ok := g.Nodes[edge.FromID]; !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown from node", nil)
+		}
+		if _, ok := g.Nodes[edge.ToID]; !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     fmt.Sprintf("e%d", i+1),
+			Source: edge.FromID,
+			Target: edge.ToID,
+			Weight: edgeWeight(edge),
+			Values: []gexfValue{{For: "edge_kind", Value: string(edge.Kind)}},
+		})
+	}
+
+	return encodeXML(doc, "GEXF")
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestGEXFDeclaresAttributesAndDirectedGraph(t *testing.T) {
+	out, err := GEXF(metadataGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`<gexf xmlns="http://gexf.net/1.3" version="1.3">`,
+		`<graph defaultedgetype="directed" mode="static">`,
+		`<attribute id="kind" title="kind" type="string"></attribute>`,
+		`<attribute id="test" title="test" type="boolean">`,
+		`<attributes class="edge">`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestGEXFExportsNodesEdgesAndWeights(t *testing.T) {
+	out, err := GEXF(metadataGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc gexfDocument
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 3 || doc.Graph.Nodes[0].Label != "@nio//:NIO & <co>" {
+		t.Fatalf("unexpected nodes: %#v", doc.Graph.Nodes)
+	}
+	lib := doc.Graph.Nodes[1]
+	if len(lib.Values) != 3 || lib.Values[2] != (gexfValue{For: "native_type", Value: "swift_library"}) {
+		t.Fatalf("unexpected library attributes: %#v", lib.Values)
+	}
+	if edge := doc.Graph.Edges[0]; edge.Weight != 2 || edge.Values[0].Value != "product" {
+		t.Fatalf("unexpected edge: %#v", edge)
+	}
+	if edge := doc.Graph.Edges[1]; edge.Weight != 1 || edge.Values[0].Value != "target" {
+		t.Fatalf("unexpected edge: %#v", edge)
+	}
+}
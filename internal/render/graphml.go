@@ -0,0 +1,146 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+type graphMLKey struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr"`
+	Name    string `xml:"attr.name,attr"`
+	Type    string `xml:"attr.type,attr"`
+	Default string `xml:"default,omitempty"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLDocument struct {
+	XMLName        xml.Name     `xml:"graphml"`
+	XMLNS          string       `xml:"xmlns,attr"`
+	XSI            string       `xml:"xmlns:xsi,attr"`
+	SchemaLocation string       `xml:"xsi:schemaLocation,attr"`
+	Keys           []graphMLKey `xml:"key"`
+	Graph          graphMLGraph `xml:"graph"`
+}
+
+// graphMLKeys declares the typed node and edge attributes shared by GraphML and GEXF.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "kind", For: "node", Name: "kind", Type: "string"},
+	{ID: "package", For: "node", Name: "package", Type: "string"},
+	{ID: "project", For: "node", Name: "project", Type: "string"},
+	{ID: "path", For: "node", Name: "path", Type: "string"},
+	{ID: "type", For: "node", Name: "type", Type: "string"},
+	{ID: "native_type", For: "node", Name: "native_type", Type: "string"},
+	{ID: "test", For: "node", Name: "test", Type: "boolean", Default: "false"},
+	{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"},
+	{ID: "weight", For: "edge", Name: "weight", Type: "int", Default: "1"},
+}
+
+// nodeAttributes lists the non-empty attribute values of a node keyed by attribute ID.
+func nodeAttributes(node graph.Node) [][2]string {
+	attrs := [][2]string{
+		{"label", node.Label},
+		{"kind", string(node.Kind)},
+		{"package", node.Package},
+		{"project", node.Project},
+		{"path", node.Path},
+		{"type", string(node.Type)},
+		{"native_type", node.NativeType},
+	}
+	out := make([][2]string, 0, len(attrs)+1)
+	for _, attr := range attrs {
+		if attr[1] != "" {
+			out = append(out, attr)
+		}
+	}
+	if node.Test {
+		out = append(out, [2]string{"test", "true"})
+	}
+	return out
+}
+
+func edgeWeight(edge graph.Edge) int {
+	if edge.Weight > 1 {
+		return edge.Weight
+	}
+	return 1
+}
+
+func encodeXML(doc interface{}, name string) (string, error) {
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed to encode %s", name), err)
+	}
+	return xml.Header + string(data), nil
+}
+
+// GraphML renders a dependency graph as GraphML with typed node and edge attributes.
+func GraphML(g graph.Graph) (string, error) {
+	doc := graphMLDocument{
+		XMLNS:          "http://graphml.graphdrawing.org/xmlns",
+		XSI:            "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd",
+		Keys:           graphMLKeys,
+		Graph:          graphMLGraph{ID: "dependencies", EdgeDefault: "directed"},
+	}
+
+	for _, id := range graph.SortedNodeIDs(g) {
+		node, ok := g.Nodes[id]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		out := graphMLNode{ID: node.ID}
+		for _, attr := range nodeAttributes(node) {
+			out.Data = append(out.Data, graphMLData{Key: attr[0], Value: attr[1]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, out)
+	}
+
+	for i, edge := range graph.SortedEdges(g) {
+		if _, ok := g.Nodes[edge.FromID]; !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown from node", nil)
+		}
+		if _, ok := g.Nodes[edge.ToID]; !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i+1),
+			Source: edge.FromID,
+			Target: edge.ToID,
+			Data: []graphMLData{
+				{Key: "edge_kind", Value: string(edge.Kind)},
+				{Key: "weight", Value: strconv.Itoa(edgeWeight(edge))},
+			},
+		})
+	}
+
+	return encodeXML(doc, "GraphML")
+}
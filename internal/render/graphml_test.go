@@ -0,0 +1,72 @@
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func metadataGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target:://app:lib":      {ID: "target:://app:lib", Label: "//app:lib", Kind: graph.NodeKindTarget, Path: "app", NativeType: "swift_library"},
+			"target:://app:lib_test": {ID: "target:://app:lib_test", Label: "//app:lib_test", Kind: graph.NodeKindTarget, Test: true, NativeType: "swift_test"},
+			"external::@nio//:NIO":   {ID: "external::@nio//:NIO", Label: "@nio//:NIO & <co>", Kind: graph.NodeKindExternalProduct, Package: "@nio"},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target:://app:lib", ToID: "external::@nio//:NIO", Kind: graph.EdgeKindProduct, Weight: 2},
+			{FromID: "target:://app:lib_test", ToID: "target:://app:lib", Kind: graph.EdgeKindTarget},
+		},
+	}
+}
+
+func TestGraphMLDeclaresTypedKeys(t *testing.T) {
+	out, err := GraphML(metadataGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`,
+		`<key id="native_type" for="node" attr.name="native_type" attr.type="string"></key>`,
+		`<key id="test" for="node" attr.name="test" attr.type="boolean">`,
+		`<key id="weight" for="edge" attr.name="weight" attr.type="int">`,
+		`<graph id="dependencies" edgedefault="directed">`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestGraphMLExportsNodeAndEdgeData(t *testing.T) {
+	out, err := GraphML(metadataGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var doc graphMLDocument
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("unexpected graph size: %#v", doc.Graph)
+	}
+	external := doc.Graph.Nodes[0]
+	if external.ID != "external::@nio//:NIO" || external.Data[0].Value != "@nio//:NIO & <co>" {
+		t.Fatalf("expected escaped label to round trip, got %#v", external)
+	}
+	testNode := doc.Graph.Nodes[2]
+	last := testNode.Data[len(testNode.Data)-1]
+	if last.Key != "test" || last.Value != "true" {
+		t.Fatalf("expected test attribute, got %#v", testNode.Data)
+	}
+	if !strings.Contains(out, `<data key="native_type">swift_test</data>`) {
+		t.Fatalf("expected rule kind attribute in:\n%s", out)
+	}
+	edge := doc.Graph.Edges[0]
+	if edge.Source != "target:://app:lib" || edge.Data[0].Value != "product" || edge.Data[1].Value != "2" {
+		t.Fatalf("unexpected edge: %#v", edge)
+	}
+}
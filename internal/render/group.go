@@ -0,0 +1,40 @@
+package render
+
+import (
+	"sort"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+// nodeGroup is a named set of node IDs rendered as one cluster.
+type nodeGroup struct {
+	Name string
+	IDs  []string
+}
+
+// partitionNodes splits the sorted node IDs of g into groups ordered by name and the
+// remaining ungrouped IDs. groups maps node IDs to group names.
+func partitionNodes(g graph.Graph, groups map[string]string) ([]nodeGroup, []string) {
+	byName := make(map[string][]string)
+	ungrouped := make([]string, 0)
+	for _, id := range graph.SortedNodeIDs(g) {
+		name, ok := groups[id]
+		if !ok || name == "" {
+			ungrouped = append(ungrouped, id)
+			continue
+		}
+		byName[name] = append(byName[name], id)
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]nodeGroup, 0, len(names))
+	for _, name := range names {
+		out = append(out, nodeGroup{Name: name, IDs: byName[name]})
+	}
+	return out, ungrouped
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+//go:embed viewer/index.html
+var viewerPage string
+
+//go:embed viewer/viewer.css
+var viewerCSS string
+
+//go:embed viewer/viewer.js
+var viewerJS string
+
+// HTMLTitle is the page title of the HTML viewer.
+const HTMLTitle = "swift-deps-diagram"
+
+// HTML renders a self-contained page with the graph embedded as JSON and the bundled
+// viewer script, so the file can be opened offline without a server.
+func HTML(g graph.Graph) (string, error) {
+	doc, err := toJSONGraph(g)
+	if err != nil {
+		return "", err
+	}
+	// json.Marshal escapes <, > and & so the data cannot close the script element.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to encode graph JSON", err)
+	}
+
+	replacer := strings.NewReplacer(
+		"{{TITLE}}", html.EscapeString(HTMLTitle),
+		"{{CSS}}", viewerCSS,
+		"{{JS}}", viewerJS,
+		"{{GRAPH}}", string(data),
+	)
+	return replacer.Replace(viewerPage), nil
+}
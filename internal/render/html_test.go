@@ -0,0 +1,50 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func TestHTMLEmbedsGraphAndViewer(t *testing.T) {
+	out, err := HTML(sampleGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		"<!DOCTYPE html>",
+		`<script id="graph-data" type="application/json">{"nodes":[{"id":"pkg::x::ExternalLib"`,
+		`id="toggle-external"`,
+		`id="toggle-tests"`,
+		`id="search"`,
+		"function layout(g)",
+		".node.selected rect",
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in HTML output", part)
+		}
+	}
+	if strings.Contains(out, "{{") {
+		t.Fatal("expected every template placeholder to be replaced")
+	}
+	if strings.Contains(out, "src=") || strings.Contains(out, "href=\"http") {
+		t.Fatal("expected no external resources")
+	}
+}
+
+func TestHTMLEscapesScriptTerminators(t *testing.T) {
+	g := graph.Graph{Nodes: map[string]graph.Node{
+		"target::X": {ID: "target::X", Label: "</script><b>", Kind: graph.NodeKindTarget},
+	}}
+	out, err := HTML(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out, "</script><b>") {
+		t.Fatal("expected label to be escaped inside embedded JSON")
+	}
+	if !strings.Contains(out, `\u003c/script\u003e\u003cb\u003e`) {
+		t.Fatal("expected unicode-escaped label in embedded JSON")
+	}
+}
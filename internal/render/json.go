@@ -0,0 +1,75 @@
+package render
+
+import (
+	"encoding/json"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+type jsonNode struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Kind    string `json:"kind"`
+	Package string `json:"package,omitempty"`
+	Project string `json:"project,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Test    bool   `json:"test,omitempty"`
+	// Type is the normalized graph.TargetType.
+	Type string `json:"type,omitempty"`
+	// NativeType is the SwiftPM target type, Xcode product type, or Bazel rule kind.
+	NativeType string `json:"native_type,omitempty"`
+}
+
+type jsonEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Kind   string `json:"kind"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+type jsonGraph struct {
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+func toJSONGraph(g graph.Graph) (jsonGraph, error) {
+	out := jsonGraph{
+		Nodes: make([]jsonNode, 0, len(g.Nodes)),
+		Edges: make([]jsonEdge, 0, len(g.Edges)),
+	}
+	for _, id := range graph.SortedNodeIDs(g) {
+		node, ok := g.Nodes[id]
+		if !ok {
+			return jsonGraph{}, apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		out.Nodes = append(out.Nodes, jsonNode{
+			ID:         node.ID,
+			Label:      node.Label,
+			Kind:       string(node.Kind),
+			Package:    node.Package,
+			Project:    node.Project,
+			Path:       node.Path,
+			Test:       node.Test,
+			Type:       string(node.Type),
+			NativeType: node.NativeType,
+		})
+	}
+	for _, edge := range graph.SortedEdges(g) {
+		out.Edges = append(out.Edges, jsonEdge{From: edge.FromID, To: edge.ToID, Kind: string(edge.Kind), Weight: edge.Weight})
+	}
+	return out, nil
+}
+
+// JSON renders the graph as a document with sorted "nodes" and "edges" arrays.
+func JSON(g graph.Graph) (string, error) {
+	doc, err := toJSONGraph(g)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", apperrors.New(apperrors.KindRuntime, "failed to encode graph JSON", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,31 @@
+package render
+
+import (
+	"encoding/json"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func TestJSONListsSortedNodesAndEdges(t *testing.T) {
+	g := sampleGraph()
+	g.Nodes["target::AppTests"] = graph.Node{ID: "target::AppTests", Label: "AppTests", Kind: graph.NodeKindTarget, Test: true, Path: "Tests/AppTests", Type: graph.TargetTypeTest}
+	out, err := JSON(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded jsonGraph
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Nodes) != 4 || decoded.Nodes[0].ID != "pkg::x::ExternalLib" || decoded.Nodes[0].Kind != "external_product" {
+		t.Fatalf("unexpected nodes: %#v", decoded.Nodes)
+	}
+	if tests := decoded.Nodes[2]; tests.ID != "target::AppTests" || !tests.Test || tests.Path != "Tests/AppTests" || tests.Type != "test" {
+		t.Fatalf("expected test node fields, got %#v", tests)
+	}
+	if len(decoded.Edges) != 2 || decoded.Edges[0].From != "target::App" || decoded.Edges[0].To != "pkg::x::ExternalLib" {
+		t.Fatalf("unexpected edges: %#v", decoded.Edges)
+	}
+}
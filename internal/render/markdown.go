@@ -0,0 +1,189 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// MarkdownOptions customize Markdown reports.
+type MarkdownOptions struct {
+	// Title names the project in the report heading.
+	Title string
+	// Versions maps lowercased package identities to their resolved versions.
+	Versions map[string]string
+	// Mermaid configures the embedded diagram.
+	Mermaid MermaidOptions
+}
+
+// escapeMarkdownCell keeps a value on one table row and out of the column syntax.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// markdownList joins the sorted labels of ids, or returns a dash when ids is empty.
+func markdownList(g graph.Graph, ids []string) string {
+	if len(ids) == 0 {
+		return "—"
+	}
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		labels = append(labels, g.Nodes[id].Label)
+	}
+	sort.Strings(labels)
+	return escapeMarkdownCell(strings.Join(labels, ", "))
+}
+
+type markdownPackage struct {
+	products []string
+	users    map[string]struct{}
+}
+
+// Markdown renders a dependency report: an embedded Mermaid diagram, a table of
+// targets, a table of external packages, and the cycles and orphan targets found.
+func Markdown(g graph.Graph, opts MarkdownOptions) (string, error) {
+	diagram, err := MermaidWithOptions(g, opts.Mermaid)
+	if err != nil {
+		return "", err
+	}
+
+	for _, edge := range g.Edges {
+		_, fromOK := g.Nodes[edge.FromID]
+		_, toOK := g.Nodes[edge.ToID]
+		if !fromOK || !toOK {
+			return "", apperrors.New(apperrors.KindRuntime, "graph contains edge to missing node", nil)
+		}
+	}
+	ids := graph.SortedNodeIDs(g)
+	successors := graph.Successors(g)
+	predecessors := graph.Predecessors(g)
+
+	targets := make([]string, 0)
+	packages := make(map[string]*markdownPackage)
+	for _, id := range ids {
+		node := g.Nodes[id]
+		if node.Kind == graph.NodeKindTarget {
+			targets = append(targets, id)
+			continue
+		}
+		name := node.Package
+		if name == "" {
+			name = node.Label
+		}
+		pkg, ok := packages[name]
+		if !ok {
+			pkg = &markdownPackage{users: make(map[string]struct{})}
+			packages[name] = pkg
+		}
+		pkg.products = append(pkg.products, id)
+		for _, user := range predecessors[id] {
+			pkg.users[user] = struct{}{}
+		}
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return g.Nodes[targets[i]].Label < g.Nodes[targets[j]].Label
+	})
+
+	title := opts.Title
+	if title == "" {
+		title = "Project"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# %s dependencies\n\n", title))
+	b.WriteString(fmt.Sprintf("%d targets, %d external packages, %d dependencies.\n\n", len(targets), len(packages), len(g.Edges)))
+
+	b.WriteString("## Diagram\n\n")
+	b.WriteString("```mermaid\n")
+	b.WriteString(diagram)
+	b.WriteString("\n```\n\n")
+
+	b.WriteString("## Targets\n\n")
+	if len(targets) == 0 {
+		b.WriteString("No targets.\n\n")
+	} else {
+		b.WriteString("| Target | Type | Dependencies | Dependents |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, id := range targets {
+			node := g.Nodes[id]
+			targetType := string(node.Type)
+			if targetType == "" {
+				targetType = node.NativeType
+			}
+			if targetType == "" {
+				targetType = "—"
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				escapeMarkdownCell(node.Label),
+				escapeMarkdownCell(targetType),
+				markdownList(g, successors[id]),
+				markdownList(g, predecessors[id]),
+			))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## External packages\n\n")
+	if len(packages) == 0 {
+		b.WriteString("No external packages.\n\n")
+	} else {
+		names := make([]string, 0, len(packages))
+		for name := range packages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString("| Package | Version | Products | Used by |\n")
+		b.WriteString("| --- | --- | --- | --- |\n")
+		for _, name := range names {
+			pkg := packages[name]
+			version := opts.Versions[strings.ToLower(name)]
+			if version == "" {
+				version = "—"
+			}
+			users := make([]string, 0, len(pkg.users))
+			for user := range pkg.users {
+				users = append(users, user)
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+				escapeMarkdownCell(name),
+				escapeMarkdownCell(version),
+				markdownList(g, pkg.products),
+				markdownList(g, users),
+			))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Cycles\n\n")
+	cycles := graph.Cycles(g)
+	if len(cycles) == 0 {
+		b.WriteString("No cycles.\n\n")
+	} else {
+		for _, cycle := range cycles {
+			b.WriteString(fmt.Sprintf("- %s\n", markdownList(g, cycle)))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Orphan targets\n\n")
+	orphans := make([]string, 0)
+	for _, id := range targets {
+		if len(successors[id]) == 0 && len(predecessors[id]) == 0 {
+			orphans = append(orphans, id)
+		}
+	}
+	if len(orphans) == 0 {
+		b.WriteString("No orphan targets.\n")
+	} else {
+		for _, id := range orphans {
+			b.WriteString(fmt.Sprintf("- %s\n", escapeMarkdownCell(g.Nodes[id].Label)))
+		}
+	}
+	return b.String(), nil
+}
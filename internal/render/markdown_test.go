@@ -0,0 +1,93 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func reportGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":          {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget, NativeType: "executable"},
+			"target::Core":         {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget, NativeType: "regular"},
+			"target::Feature":      {ID: "target::Feature", Label: "Feature", Kind: graph.NodeKindTarget, NativeType: "regular"},
+			"target::Scratch":      {ID: "target::Scratch", Label: "Scratch", Kind: graph.NodeKindTarget},
+			"pkg::swift-nio::NIO":  {ID: "pkg::swift-nio::NIO", Label: "NIO", Kind: graph.NodeKindExternalProduct, Package: "swift-nio"},
+			"pkg::swift-nio::Core": {ID: "pkg::swift-nio::Core", Label: "NIOCore", Kind: graph.NodeKindExternalProduct, Package: "swift-nio"},
+			"name::Logging":        {ID: "name::Logging", Label: "Logging", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::App", ToID: "target::Feature", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "target::Feature", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Feature", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::swift-nio::NIO", Kind: graph.EdgeKindProduct},
+			{FromID: "target::Feature", ToID: "pkg::swift-nio::Core", Kind: graph.EdgeKindProduct},
+			{FromID: "target::App", ToID: "name::Logging", Kind: graph.EdgeKindByName},
+		},
+	}
+}
+
+func TestMarkdownRendersReportSections(t *testing.T) {
+	out, err := Markdown(reportGraph(), MarkdownOptions{Title: "Demo", Versions: map[string]string{"swift-nio": "2.62.0"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"# Demo dependencies\n\n4 targets, 2 external packages, 7 dependencies.\n",
+		"## Diagram\n\n```mermaid\nflowchart TD\n",
+		"| Target | Type | Dependencies | Dependents |\n| --- | --- | --- | --- |\n" +
+			"| App | executable | Core, Feature, Logging | — |\n" +
+			"| Core | regular | Feature, NIO | App, Feature |\n" +
+			"| Feature | regular | Core, NIOCore | App, Core |\n" +
+			"| Scratch | — | — | — |\n",
+		"| Package | Version | Products | Used by |\n| --- | --- | --- | --- |\n" +
+			"| Logging | — | Logging | App |\n" +
+			"| swift-nio | 2.62.0 | NIO, NIOCore | Core, Feature |\n",
+		"## Cycles\n\n- Core, Feature\n",
+		"## Orphan targets\n\n- Scratch\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in report:\n%s", want, out)
+		}
+	}
+}
+
+func TestMarkdownReportsEmptySections(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::A": {ID: "target::A", Label: "A|B", Kind: graph.NodeKindTarget},
+			"target::C": {ID: "target::C", Label: "C", Kind: graph.NodeKindTarget},
+		},
+		Edges: []graph.Edge{{FromID: "target::A", ToID: "target::C", Kind: graph.EdgeKindTarget}},
+	}
+	out, err := Markdown(g, MarkdownOptions{Mermaid: MermaidOptions{Direction: "LR"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"# Project dependencies",
+		"flowchart LR",
+		"| A\\|B | — | C | — |",
+		"No external packages.",
+		"No cycles.",
+		"No orphan targets.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in report:\n%s", want, out)
+		}
+	}
+}
+
+func TestMarkdownRejectsMissingNodes(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{"target::A": {ID: "target::A", Label: "A", Kind: graph.NodeKindTarget}},
+		Edges: []graph.Edge{{FromID: "target::A", ToID: "target::B", Kind: graph.EdgeKindTarget}},
+	}
+	if _, err := Markdown(g, MarkdownOptions{}); !apperrors.IsKind(err, apperrors.KindRuntime) {
+		t.Fatalf("expected runtime kind, got %v", err)
+	}
+}
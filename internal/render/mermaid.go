@@ -15,32 +15,200 @@ func escapeMermaidLabel(s string) string {
 	return s
 }
 
+// MermaidDirections lists the supported flowchart directions.
+var MermaidDirections = []string{"TD", "LR", "BT", "RL"}
+
+// IsValidMermaidDirection reports whether direction is a supported flowchart direction.
+func IsValidMermaidDirection(direction string) bool {
+	for _, supported := range MermaidDirections {
+		if direction == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// MermaidOptions customize Mermaid rendering.
+type MermaidOptions struct {
+	// Highlight lists node IDs drawn with emphasis.
+	Highlight []string
+	// Groups maps node IDs to subgraph names.
+	Groups map[string]string
+	// Direction is the flowchart direction; empty means TD.
+	Direction string
+	// LinkBase, when set, adds a click link to LinkBase/<path> for every node with a
+	// source path. It may be a repository URL or a local directory.
+	LinkBase string
+}
+
+// mermaidTypeShapes holds the opening and closing delimiters for target types that are
+// not drawn as plain boxes.
+var mermaidTypeShapes = map[graph.TargetType][2]string{
+	graph.TargetTypeApp:           {"{{", "}}"},
+	graph.TargetTypeAppExtension:  {">", "]"},
+	graph.TargetTypeFramework:     {"[[", "]]"},
+	graph.TargetTypeExecutable:    {"[/", "/]"},
+	graph.TargetTypeTest:          {"(", ")"},
+	graph.TargetTypeUITest:        {"(", ")"},
+	graph.TargetTypeMacro:         {"[\\", "\\]"},
+	graph.TargetTypePlugin:        {"[/", "\\]"},
+	graph.TargetTypeBinary:        {"[\\", "/]"},
+	graph.TargetTypeSystemLibrary: {"[(", ")]"},
+	graph.TargetTypeAggregate:     {"((", "))"},
+}
+
+// mermaidNodeShape wraps a label in the node shape for node: stadiums for external
+// products, mirroring the DOT ellipses, and boxes for targets unless their type has a
+// shape of its own.
+func mermaidNodeShape(node graph.Node, label string) string {
+	if node.Kind == graph.NodeKindExternalProduct {
+		return "([\"" + label + "\"])"
+	}
+	if shape, ok := mermaidTypeShapes[node.Type]; ok {
+		return shape[0] + "\"" + label + "\"" + shape[1]
+	}
+	return "[\"" + label + "\"]"
+}
+
+// mermaidArrow returns the link syntax for kind: by-name dependencies are dotted.
+func mermaidArrow(kind graph.EdgeKind) string {
+	if kind == graph.EdgeKindByName {
+		return "-.->"
+	}
+	return "-->"
+}
+
+var mermaidClassDefs = []struct {
+	kind  graph.NodeKind
+	style string
+}{
+	{graph.NodeKindTarget, "fill:#eef4fb,stroke:#4c78a8,color:#1f2328"},
+	{graph.NodeKindExternalProduct, "fill:#f6f8fa,stroke:#8c959f,stroke-dasharray:4 3,color:#57606a"},
+}
+
+const mermaidProductLinkStyle = "stroke:#8c959f"
+
+func mermaidLink(base, nodePath string) string {
+	link := strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(nodePath, "/")
+	return strings.ReplaceAll(link, "\"", "%22")
+}
+
 // Mermaid renders a dependency graph in Mermaid flowchart TD format.
 func Mermaid(g graph.Graph) (string, error) {
+	return MermaidWithOptions(g, MermaidOptions{})
+}
+
+// MermaidWithOptions renders a dependency graph as a Mermaid flowchart using opts.
+func MermaidWithOptions(g graph.Graph, opts MermaidOptions) (string, error) {
 	idList := graph.SortedNodeIDs(g)
 	idMap := make(map[string]string, len(idList))
 	for i, id := range idList {
 		idMap[id] = fmt.Sprintf("n%d", i+1)
 	}
 
+	direction := opts.Direction
+	if direction == "" {
+		direction = "TD"
+	}
+	if !IsValidMermaidDirection(direction) {
+		return "", apperrors.New(apperrors.KindInvalidArgs, "mermaid direction must be one of: "+strings.Join(MermaidDirections, "|"), nil)
+	}
+
 	var b strings.Builder
-	b.WriteString("flowchart TD\n")
+	b.WriteString("flowchart " + direction + "\n")
 
-	for _, id := range idList {
+	writeNode := func(id, indent string) error {
 		node, ok := g.Nodes[id]
 		if !ok {
-			return "", apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+			return apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		b.WriteString(fmt.Sprintf("%s%s%s\n", indent, idMap[id], mermaidNodeShape(node, escapeMermaidLabel(node.Label))))
+		return nil
+	}
+
+	groups, ungrouped := partitionNodes(g, opts.Groups)
+	for i, group := range groups {
+		b.WriteString(fmt.Sprintf("    subgraph c%d[\"%s\"]\n", i+1, escapeMermaidLabel(group.Name)))
+		for _, id := range group.IDs {
+			if err := writeNode(id, "        "); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString("    end\n")
+	}
+	for _, id := range ungrouped {
+		if err := writeNode(id, "    "); err != nil {
+			return "", err
 		}
-		b.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", idMap[id], escapeMermaidLabel(node.Label)))
 	}
 
-	for _, edge := range graph.SortedEdges(g) {
+	productLinks := make([]string, 0)
+	for i, edge := range graph.SortedEdges(g) {
 		from, okFrom := idMap[edge.FromID]
 		to, okTo := idMap[edge.ToID]
 		if !okFrom || !okTo {
 			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown node", nil)
 		}
-		b.WriteString(fmt.Sprintf("    %s --> %s\n", from, to))
+		if edge.Kind == graph.EdgeKindProduct {
+			productLinks = append(productLinks, fmt.Sprint(i))
+		}
+		if edge.Weight > 1 {
+			b.WriteString(fmt.Sprintf("    %s %s|%d| %s\n", from, mermaidArrow(edge.Kind), edge.Weight, to))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("    %s %s %s\n", from, mermaidArrow(edge.Kind), to))
+	}
+	if len(productLinks) > 0 {
+		b.WriteString(fmt.Sprintf("    linkStyle %s %s\n", strings.Join(productLinks, ","), mermaidProductLinkStyle))
+	}
+
+	for _, classDef := range mermaidClassDefs {
+		members := make([]string, 0)
+		for _, id := range idList {
+			if g.Nodes[id].Kind == classDef.kind {
+				members = append(members, idMap[id])
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("    classDef %s %s\n", classDef.kind, classDef.style))
+		b.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(members, ","), classDef.kind))
+	}
+	for _, style := range targetTypeStyles {
+		members := make([]string, 0)
+		for _, id := range idList {
+			if node := g.Nodes[id]; node.Kind == graph.NodeKindTarget && node.Type == style.targetType {
+				members = append(members, idMap[id])
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		name := "type_" + string(style.targetType)
+		b.WriteString(fmt.Sprintf("    classDef %s fill:%s,stroke:%s\n", name, style.fill, style.stroke))
+		b.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(members, ","), name))
+	}
+
+	if opts.LinkBase != "" {
+		for _, id := range idList {
+			node := g.Nodes[id]
+			if node.Path == "" {
+				continue
+			}
+			b.WriteString(fmt.Sprintf("    click %s href \"%s\" _blank\n", idMap[id], mermaidLink(opts.LinkBase, node.Path)))
+		}
+	}
+
+	highlighted := make([]string, 0, len(opts.Highlight))
+	for _, id := range opts.Highlight {
+		if alias, ok := idMap[id]; ok {
+			highlighted = append(highlighted, alias)
+		}
+	}
+	if len(highlighted) > 0 {
+		b.WriteString("    classDef highlight stroke:#d62728,stroke-width:3px\n")
+		b.WriteString(fmt.Sprintf("    class %s highlight\n", strings.Join(highlighted, ",")))
 	}
 
 	return strings.TrimSpace(b.String()), nil
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	apperrors "swift-deps-diagram/internal/errors"
 	"swift-deps-diagram/internal/graph"
 )
 
@@ -82,3 +83,123 @@ func TestMermaidDeterministicOutput(t *testing.T) {
 		t.Fatalf("expected deterministic output")
 	}
 }
+
+func TestMermaidWithOptionsHighlightsNodes(t *testing.T) {
+	out, err := MermaidWithOptions(sampleGraph(), MermaidOptions{Highlight: []string{"target::App", "target::Missing"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "classDef highlight") || !strings.HasSuffix(out, "class n2 highlight") {
+		t.Fatalf("expected highlight class for App, got %q", out)
+	}
+}
+
+func TestMermaidLabelsWeightedEdges(t *testing.T) {
+	g := sampleGraph()
+	g.Edges[1].Weight = 2
+	out, err := Mermaid(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "n2 -->|2| n1") {
+		t.Fatalf("expected weighted edge label, got %q", out)
+	}
+}
+
+func TestMermaidWithOptionsDrawsSubgraphs(t *testing.T) {
+	groups := map[string]string{"target::App": "Sample", "target::Core": "Sample"}
+	out, err := MermaidWithOptions(sampleGraph(), MermaidOptions{Groups: groups})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "    subgraph c1[\"Sample\"]\n        n2[\"App\"]\n        n3[\"Core\"]\n    end\n    n1([\"ExternalLib\"])\n"
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected grouped targets in subgraph, got %q", out)
+	}
+	if !strings.Contains(out, "n2 --> n3") {
+		t.Fatalf("expected edges to keep global node IDs, got %q", out)
+	}
+}
+
+func TestMermaidStylesNodesAndEdgesByKind(t *testing.T) {
+	g := sampleGraph()
+	g.Nodes["target::Util"] = graph.Node{ID: "target::Util", Label: "Util", Kind: graph.NodeKindTarget}
+	g.Edges = append(g.Edges, graph.Edge{FromID: "target::Core", ToID: "target::Util", Kind: graph.EdgeKindByName})
+	out, err := Mermaid(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"    n1([\"ExternalLib\"])\n",
+		"    n2 --> n1\n",
+		"    n2 --> n3\n",
+		"    n3 -.-> n4\n",
+		"    linkStyle 0 stroke:#8c959f\n",
+		"    classDef target ",
+		"    class n2,n3,n4 target\n",
+		"    classDef external_product ",
+		"    class n1 external_product",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got %q", want, out)
+		}
+	}
+}
+
+func TestMermaidShapesAndColorsTargetTypes(t *testing.T) {
+	g := sampleGraph()
+	app := g.Nodes["target::App"]
+	app.Type = graph.TargetTypeApp
+	g.Nodes["target::App"] = app
+	core := g.Nodes["target::Core"]
+	core.Type = graph.TargetTypeLibrary
+	g.Nodes["target::Core"] = core
+	g.Nodes["target::CoreTests"] = graph.Node{ID: "target::CoreTests", Label: "CoreTests", Kind: graph.NodeKindTarget, Type: graph.TargetTypeTest}
+	out, err := Mermaid(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"    n2{{\"App\"}}\n",
+		"    n3[\"Core\"]\n",
+		"    n4(\"CoreTests\")\n",
+		"    class n2,n3,n4 target\n",
+		"    classDef type_app fill:#dcf2e3,stroke:#2da44e\n",
+		"    class n2 type_app\n",
+		"    class n4 type_test",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "type_library") {
+		t.Fatalf("expected libraries to keep the target class, got %q", out)
+	}
+}
+
+func TestMermaidWithOptionsDirectionAndLinks(t *testing.T) {
+	g := sampleGraph()
+	app := g.Nodes["target::App"]
+	app.Path = "Sources/App"
+	g.Nodes["target::App"] = app
+
+	out, err := MermaidWithOptions(g, MermaidOptions{Direction: "LR", LinkBase: "https://example.com/repo/tree/main/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "flowchart LR\n") {
+		t.Fatalf("expected LR header, got %q", out)
+	}
+	if !strings.Contains(out, "    click n2 href \"https://example.com/repo/tree/main/Sources/App\" _blank") {
+		t.Fatalf("expected click link for App, got %q", out)
+	}
+	if strings.Count(out, "click ") != 1 {
+		t.Fatalf("expected links only for nodes with a path, got %q", out)
+	}
+
+	if _, err := MermaidWithOptions(g, MermaidOptions{Direction: "UP"}); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+		t.Fatalf("expected invalid args kind for bad direction, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// plantUMLEscaper replaces characters that PlantUML would treat as string delimiters,
+// escapes, HTML-like tags, or entities with numeric character references. The replacer
+// works in a single pass, so the inserted references are not escaped again.
+var plantUMLEscaper = strings.NewReplacer(
+	"&", "&#38;",
+	"~", "&#126;",
+	"\"", "&#34;",
+	"\\", "&#92;",
+	"<", "&#60;",
+	">", "&#62;",
+	"\n", " ",
+)
+
+// plantUMLMarkup lists the doubled creole markers (italic, bold, underline, strike,
+// wave, monospace) that are broken up with the "~" escape character.
+var plantUMLMarkup = []string{"//", "**", "__", "--", "==", "^^"}
+
+func escapePlantUML(s string) string {
+	s = plantUMLEscaper.Replace(s)
+	for _, marker := range plantUMLMarkup {
+		s = strings.ReplaceAll(s, marker, "~"+marker)
+	}
+	return s
+}
+
+func plantUMLStereotype(kind graph.NodeKind) string {
+	switch kind {
+	case graph.NodeKindExternalProduct:
+		return "<<external>>"
+	default:
+		return "<<target>>"
+	}
+}
+
+// plantUMLTypeElements holds the element keyword for target types that are not drawn
+// as plain components.
+var plantUMLTypeElements = map[graph.TargetType]string{
+	graph.TargetTypeApp:           "node",
+	graph.TargetTypeFramework:     "folder",
+	graph.TargetTypeExecutable:    "artifact",
+	graph.TargetTypeTest:          "card",
+	graph.TargetTypeUITest:        "card",
+	graph.TargetTypeMacro:         "hexagon",
+	graph.TargetTypePlugin:        "agent",
+	graph.TargetTypeBinary:        "file",
+	graph.TargetTypeSystemLibrary: "database",
+	graph.TargetTypeAggregate:     "collections",
+}
+
+// plantUMLElement returns the element declaration suffix for node: its keyword, and the
+// fill color of its target type after the stereotype.
+func plantUMLElement(node graph.Node) (string, string) {
+	element := "component"
+	if keyword, ok := plantUMLTypeElements[node.Type]; ok && node.Kind == graph.NodeKindTarget {
+		element = keyword
+	}
+	if style, ok := targetStyle(node); ok {
+		return element, " " + strings.ToUpper(style.fill)
+	}
+	return element, ""
+}
+
+func plantUMLArrow(kind graph.EdgeKind) string {
+	switch kind {
+	case graph.EdgeKindProduct:
+		return "..>"
+	case graph.EdgeKindByName:
+		return "-[dotted]->"
+	default:
+		return "-->"
+	}
+}
+
+// PlantUMLOptions customize PlantUML rendering.
+type PlantUMLOptions struct {
+	// Groups maps node IDs to package names.
+	Groups map[string]string
+}
+
+// PlantUML renders a dependency graph as a PlantUML component diagram.
+func PlantUML(g graph.Graph) (string, error) {
+	return PlantUMLWithOptions(g, PlantUMLOptions{})
+}
+
+// PlantUMLWithOptions renders a dependency graph as a PlantUML component diagram using opts.
+func PlantUMLWithOptions(g graph.Graph, opts PlantUMLOptions) (string, error) {
+	idList := graph.SortedNodeIDs(g)
+	idMap := make(map[string]string, len(idList))
+	for i, id := range idList {
+		idMap[id] = fmt.Sprintf("n%d", i+1)
+	}
+
+	var b strings.Builder
+	b.WriteString("@startuml\n")
+	b.WriteString("skinparam componentStyle rectangle\n")
+	b.WriteString("skinparam component<<external>> {\n")
+	b.WriteString("  BackgroundColor #F6F8FA\n")
+	b.WriteString("  BorderStyle dashed\n")
+	b.WriteString("}\n")
+
+	writeNode := func(id, indent string) error {
+		node, ok := g.Nodes[id]
+		if !ok {
+			return apperrors.New(apperrors.KindRuntime, "graph contains missing node", nil)
+		}
+		element, color := plantUMLElement(node)
+		b.WriteString(fmt.Sprintf("%s%s \"%s\" as %s %s%s\n", indent, element, escapePlantUML(node.Label), idMap[id], plantUMLStereotype(node.Kind), color))
+		return nil
+	}
+
+	groups, ungrouped := partitionNodes(g, opts.Groups)
+	for _, group := range groups {
+		b.WriteString(fmt.Sprintf("package \"%s\" {\n", escapePlantUML(group.Name)))
+		for _, id := range group.IDs {
+			if err := writeNode(id, "  "); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString("}\n")
+	}
+	for _, id := range ungrouped {
+		if err := writeNode(id, ""); err != nil {
+			return "", err
+		}
+	}
+
+	for _, edge := range graph.SortedEdges(g) {
+		fromID, ok := idMap[edge.FromID]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown from node", nil)
+		}
+		toID, ok := idMap[edge.ToID]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
+		}
+		if edge.Weight > 1 {
+			b.WriteString(fmt.Sprintf("%s %s %s : %d\n", fromID, plantUMLArrow(edge.Kind), toID, edge.Weight))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s %s %s\n", fromID, plantUMLArrow(edge.Kind), toID))
+	}
+
+	b.WriteString("@enduml")
+	return b.String(), nil
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"swift-deps-diagram/internal/graph"
+)
+
+func TestPlantUMLWrapsComponentDiagram(t *testing.T) {
+	out, err := PlantUML(sampleGraph())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out, "@startuml\n") || !strings.HasSuffix(out, "\n@enduml") {
+		t.Fatalf("expected @startuml/@enduml block, got %q", out)
+	}
+	for _, part := range []string{
+		`component "ExternalLib" as n1 <<external>>`,
+		`component "App" as n2 <<target>>`,
+		"n2 --> n3",
+		"n2 ..> n1",
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestPlantUMLArrowStylesAndWeights(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::A":  {ID: "target::A", Label: "A", Kind: graph.NodeKindTarget},
+			"name::B":    {ID: "name::B", Label: "B", Kind: graph.NodeKindExternalProduct},
+			"pkg::nio":   {ID: "pkg::nio", Label: "nio", Kind: graph.NodeKindExternalProduct},
+			"target::AA": {ID: "target::AA", Label: "AA", Kind: graph.NodeKindTarget},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::A", ToID: "name::B", Kind: graph.EdgeKindByName},
+			{FromID: "target::A", ToID: "pkg::nio", Kind: graph.EdgeKindProduct, Weight: 3},
+		},
+	}
+	out, err := PlantUML(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "n3 -[dotted]-> n1") || !strings.Contains(out, "n3 ..> n2 : 3") {
+		t.Fatalf("unexpected edges in:\n%s", out)
+	}
+}
+
+func TestPlantUMLEscapesLabels(t *testing.T) {
+	got := escapePlantUML("//app:lib \"x\" <b>a&b</b> \\n ~**bold**\nnext")
+	want := `~//app:lib &#34;x&#34; &#60;b&#62;a&#38;b&#60;/b&#62; &#92;n &#126;~**bold~** next`
+	if got != want {
+		t.Fatalf("unexpected escaping:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestPlantUMLWithOptionsDrawsPackages(t *testing.T) {
+	out, err := PlantUMLWithOptions(sampleGraph(), PlantUMLOptions{Groups: map[string]string{"target::App": "Sample", "target::Core": "Sample"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "package \"Sample\" {\n  component \"App\" as n2 <<target>>\n  component \"Core\" as n3 <<target>>\n}\ncomponent \"ExternalLib\" as n1 <<external>>\n"
+	if !strings.Contains(out, want) {
+		t.Fatalf("expected grouped components in package, got:\n%s", out)
+	}
+}
+
+func TestPlantUMLDrawsTargetTypes(t *testing.T) {
+	g := sampleGraph()
+	app := g.Nodes["target::App"]
+	app.Type = graph.TargetTypeApp
+	g.Nodes["target::App"] = app
+	core := g.Nodes["target::Core"]
+	core.Type = graph.TargetTypeLibrary
+	g.Nodes["target::Core"] = core
+	out, err := PlantUML(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`component "ExternalLib" as n1 <<external>>` + "\n",
+		`node "App" as n2 <<target>> #DCF2E3` + "\n",
+		`component "Core" as n3 <<target>>` + "\n",
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package render
+
+import "swift-deps-diagram/internal/graph"
+
+// targetTypeStyle holds the shared colors of a target type in the text diagram formats.
+type targetTypeStyle struct {
+	targetType graph.TargetType
+	fill       string
+	stroke     string
+}
+
+// targetTypeStyles color every target type except libraries, which keep the plain
+// target style.
+var targetTypeStyles = []targetTypeStyle{
+	{graph.TargetTypeApp, "#dcf2e3", "#2da44e"},
+	{graph.TargetTypeAppExtension, "#edf8f0", "#2da44e"},
+	{graph.TargetTypeFramework, "#dde9f7", "#0969da"},
+	{graph.TargetTypeExecutable, "#fff4d6", "#bf8700"},
+	{graph.TargetTypeTest, "#fdecea", "#cf222e"},
+	{graph.TargetTypeUITest, "#fbe3ef", "#bf3989"},
+	{graph.TargetTypeMacro, "#efe7fb", "#8250df"},
+	{graph.TargetTypePlugin, "#fbeee0", "#bc4c00"},
+	{graph.TargetTypeBinary, "#eceff1", "#57606a"},
+	{graph.TargetTypeSystemLibrary, "#f1f3f5", "#57606a"},
+	{graph.TargetTypeAggregate, "#ffffff", "#57606a"},
+}
+
+// targetStyle returns the style of node's target type, if it has one.
+func targetStyle(node graph.Node) (targetTypeStyle, bool) {
+	if node.Kind != graph.NodeKindTarget {
+		return targetTypeStyle{}, false
+	}
+	for _, style := range targetTypeStyles {
+		if style.targetType == node.Type {
+			return style, true
+		}
+	}
+	return targetTypeStyle{}, false
+}
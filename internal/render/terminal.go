@@ -28,49 +28,124 @@ func sortNodeIDsByLabelThenID(g graph.Graph, ids []string) {
 	})
 }
 
-func writeTerminalChildren(
-	b *strings.Builder,
-	childrenByFrom map[string][]terminalChild,
-	pathSeen map[string]struct{},
-	nodeID string,
-	prefix string,
-) {
-	children := childrenByFrom[nodeID]
+// TerminalOptions customize terminal tree rendering.
+type TerminalOptions struct {
+	// Unicode draws branches with box-drawing characters instead of ASCII.
+	Unicode bool
+	// Color adds ANSI colors per node kind and dims repeat markers.
+	Color bool
+	// MaxDepth limits how many levels below each root are expanded; zero means no limit.
+	MaxDepth int
+	// Invert renders dependents instead of dependencies: every node without dependencies
+	// becomes a root and its children are the targets that depend on it.
+	Invert bool
+}
+
+type terminalGlyphs struct {
+	branch, lastBranch, pipe, space, more string
+}
+
+var (
+	asciiGlyphs   = terminalGlyphs{branch: "|-- ", lastBranch: "\\-- ", pipe: "|   ", space: "    ", more: "..."}
+	unicodeGlyphs = terminalGlyphs{branch: "├── ", lastBranch: "└── ", pipe: "│   ", space: "    ", more: "…"}
+)
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiGreen   = "\x1b[32m"
+	ansiCyan    = "\x1b[36m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+)
+
+type terminalWriter struct {
+	b              *strings.Builder
+	g              graph.Graph
+	childrenByFrom map[string][]terminalChild
+	glyphs         terminalGlyphs
+	color          bool
+	maxDepth       int
+}
+
+func (w *terminalWriter) paint(code, text string) string {
+	if !w.color || code == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// nodeColor picks the ANSI color for a node reached through edgeKind: by-name
+// references are yellow whatever they resolve to, then test targets are magenta,
+// external products cyan, and other local targets green.
+func (w *terminalWriter) nodeColor(node graph.Node, edgeKind graph.EdgeKind) string {
+	switch {
+	case edgeKind == graph.EdgeKindByName:
+		return ansiYellow
+	case node.Test:
+		return ansiMagenta
+	case node.Kind == graph.NodeKindExternalProduct:
+		return ansiCyan
+	default:
+		return ansiGreen
+	}
+}
+
+func (w *terminalWriter) writeRoot(id string) {
+	node := w.g.Nodes[id]
+	w.b.WriteString(w.paint(ansiBold+w.nodeColor(node, ""), terminalLabel(node.Label)))
+}
+
+func (w *terminalWriter) writeChildren(pathSeen map[string]struct{}, nodeID, prefix string, depth int) {
+	children := w.childrenByFrom[nodeID]
 	for i, child := range children {
 		isLast := i == len(children)-1
-		branch := "|-- "
-		nextPrefix := prefix + "|   "
+		branch := w.glyphs.branch
+		nextPrefix := prefix + w.glyphs.pipe
 		if isLast {
-			branch = "\\-- "
-			nextPrefix = prefix + "    "
+			branch = w.glyphs.lastBranch
+			nextPrefix = prefix + w.glyphs.space
 		}
 
-		label := terminalLabel(child.node.Label)
+		line := "\n" + w.paint(ansiDim, prefix+branch) + w.paint(w.nodeColor(child.node, child.edge.Kind), terminalLabel(child.node.Label))
 		if _, ok := pathSeen[child.node.ID]; ok {
-			b.WriteString("\n" + prefix + branch + label + " (*)")
+			w.b.WriteString(line + " " + w.paint(ansiDim, "(*)"))
+			continue
+		}
+		if w.maxDepth > 0 && depth >= w.maxDepth {
+			if len(w.childrenByFrom[child.node.ID]) > 0 {
+				line += " " + w.paint(ansiDim, w.glyphs.more)
+			}
+			w.b.WriteString(line)
 			continue
 		}
 
-		b.WriteString("\n" + prefix + branch + label)
+		w.b.WriteString(line)
 		pathSeen[child.node.ID] = struct{}{}
-		writeTerminalChildren(b, childrenByFrom, pathSeen, child.node.ID, nextPrefix)
+		w.writeChildren(pathSeen, child.node.ID, nextPrefix, depth+1)
 		delete(pathSeen, child.node.ID)
 	}
 }
 
 // Terminal renders a dependency graph in an ASCII tree format for terminal output.
 func Terminal(g graph.Graph) (string, error) {
-	targetIDs := make([]string, 0)
-	incomingTargetEdges := make(map[string]int)
-	targetChildren := make(map[string][]string)
+	return TerminalWithOptions(g, TerminalOptions{})
+}
+
+// TerminalWithOptions renders a dependency graph as a tree for terminal output using opts.
+func TerminalWithOptions(g graph.Graph, opts TerminalOptions) (string, error) {
+	candidateIDs := make([]string, 0)
+	incomingTreeEdges := make(map[string]int)
+	treeChildren := make(map[string][]string)
 	for id, node := range g.Nodes {
-		if node.Kind != graph.NodeKindTarget {
+		if !opts.Invert && node.Kind != graph.NodeKindTarget {
 			continue
 		}
-		targetIDs = append(targetIDs, id)
-		incomingTargetEdges[id] = 0
+		candidateIDs = append(candidateIDs, id)
+		incomingTreeEdges[id] = 0
 	}
-	sortNodeIDsByLabelThenID(g, targetIDs)
+	sortNodeIDsByLabelThenID(g, candidateIDs)
 
 	childrenByFrom := make(map[string][]terminalChild)
 	for _, edge := range graph.SortedEdges(g) {
@@ -83,16 +158,21 @@ func Terminal(g graph.Graph) (string, error) {
 			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
 		}
 
-		childrenByFrom[fromNode.ID] = append(childrenByFrom[fromNode.ID], terminalChild{
+		// Inverted trees hang each dependent below the node it depends on.
+		parent, child := fromNode, toNode
+		if opts.Invert {
+			parent, child = toNode, fromNode
+		}
+		childrenByFrom[parent.ID] = append(childrenByFrom[parent.ID], terminalChild{
 			edge: edge,
-			node: toNode,
+			node: child,
 		})
 
-		if fromNode.Kind == graph.NodeKindTarget && toNode.Kind == graph.NodeKindTarget {
-			if _, exists := incomingTargetEdges[toNode.ID]; exists {
-				incomingTargetEdges[toNode.ID]++
+		if opts.Invert || (fromNode.Kind == graph.NodeKindTarget && toNode.Kind == graph.NodeKindTarget) {
+			if _, exists := incomingTreeEdges[child.ID]; exists {
+				incomingTreeEdges[child.ID]++
 			}
-			targetChildren[fromNode.ID] = append(targetChildren[fromNode.ID], toNode.ID)
+			treeChildren[parent.ID] = append(treeChildren[parent.ID], child.ID)
 		}
 	}
 
@@ -111,20 +191,20 @@ func Terminal(g graph.Graph) (string, error) {
 	}
 
 	roots := make([]string, 0)
-	for _, id := range targetIDs {
-		if incomingTargetEdges[id] == 0 {
+	for _, id := range candidateIDs {
+		if incomingTreeEdges[id] == 0 {
 			roots = append(roots, id)
 		}
 	}
 
-	coveredTargets := make(map[string]struct{})
+	covered := make(map[string]struct{})
 	var markCovered func(string)
 	markCovered = func(id string) {
-		if _, ok := coveredTargets[id]; ok {
+		if _, ok := covered[id]; ok {
 			return
 		}
-		coveredTargets[id] = struct{}{}
-		for _, childID := range targetChildren[id] {
+		covered[id] = struct{}{}
+		for _, childID := range treeChildren[id] {
 			markCovered(childID)
 		}
 	}
@@ -132,8 +212,8 @@ func Terminal(g graph.Graph) (string, error) {
 		markCovered(rootID)
 	}
 
-	for _, id := range targetIDs {
-		if _, ok := coveredTargets[id]; ok {
+	for _, id := range candidateIDs {
+		if _, ok := covered[id]; ok {
 			continue
 		}
 		roots = append(roots, id)
@@ -145,15 +225,18 @@ func Terminal(g graph.Graph) (string, error) {
 	}
 
 	var b strings.Builder
+	w := &terminalWriter{b: &b, g: g, childrenByFrom: childrenByFrom, glyphs: asciiGlyphs, color: opts.Color, maxDepth: opts.MaxDepth}
+	if opts.Unicode {
+		w.glyphs = unicodeGlyphs
+	}
 	for i, rootID := range roots {
 		if i > 0 {
 			b.WriteString("\n\n")
 		}
 
-		rootNode := g.Nodes[rootID]
-		b.WriteString(terminalLabel(rootNode.Label))
+		w.writeRoot(rootID)
 		pathSeen := map[string]struct{}{rootID: struct{}{}}
-		writeTerminalChildren(&b, childrenByFrom, pathSeen, rootID, "")
+		w.writeChildren(pathSeen, rootID, "", 1)
 	}
 	return b.String(), nil
 }
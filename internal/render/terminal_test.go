@@ -1,6 +1,7 @@
 package render
 
 import (
+	"strings"
 	"testing"
 
 	apperrors "swift-deps-diagram/internal/errors"
@@ -162,3 +163,131 @@ func TestTerminalErrorsOnMissingNodes(t *testing.T) {
 		t.Fatalf("expected runtime kind, got %v", err)
 	}
 }
+
+func styledTerminalGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":      {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::AppTests": {ID: "target::AppTests", Label: "AppTests", Kind: graph.NodeKindTarget, Test: true},
+			"target::Core":     {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"target::Util":     {ID: "target::Util", Label: "Util", Kind: graph.NodeKindTarget},
+			"pkg::x::Net":      {ID: "pkg::x::Net", Label: "Net", Kind: graph.NodeKindExternalProduct},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::App", ToID: "target::Util", Kind: graph.EdgeKindByName},
+			{FromID: "target::Core", ToID: "pkg::x::Net", Kind: graph.EdgeKindProduct},
+			{FromID: "target::Core", ToID: "target::Util", Kind: graph.EdgeKindTarget},
+			{FromID: "target::AppTests", ToID: "target::App", Kind: graph.EdgeKindTarget},
+		},
+	}
+}
+
+func TestTerminalUnicodeBranches(t *testing.T) {
+	out, err := TerminalWithOptions(styledTerminalGraph(), TerminalOptions{Unicode: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "AppTests\n" +
+		"└── App\n" +
+		"    ├── Core\n" +
+		"    │   ├── Net\n" +
+		"    │   └── Util\n" +
+		"    └── Util"
+	if out != expected {
+		t.Fatalf("unexpected unicode output:\n%s", out)
+	}
+}
+
+func TestTerminalColorsNodesByKind(t *testing.T) {
+	g := styledTerminalGraph()
+	g.Nodes["pkg::y::Log"] = graph.Node{ID: "pkg::y::Log", Label: "Log", Kind: graph.NodeKindExternalProduct}
+	g.Edges = append(g.Edges,
+		graph.Edge{FromID: "target::Util", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+		graph.Edge{FromID: "target::App", ToID: "pkg::y::Log", Kind: graph.EdgeKindByName},
+	)
+
+	out, err := TerminalWithOptions(g, TerminalOptions{Color: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		ansiBold + ansiMagenta + "AppTests" + ansiReset,
+		ansiDim + "\\-- " + ansiReset + ansiGreen + "App" + ansiReset + "\n",
+		ansiGreen + "Core" + ansiReset,
+		ansiCyan + "Net" + ansiReset,
+		ansiYellow + "Util" + ansiReset,
+		ansiYellow + "Log" + ansiReset,
+		"Core" + ansiReset + " " + ansiDim + "(*)" + ansiReset,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in colored output:\n%q", want, out)
+		}
+	}
+}
+
+func TestTerminalMaxDepthMarksTruncatedNodes(t *testing.T) {
+	out, err := TerminalWithOptions(styledTerminalGraph(), TerminalOptions{MaxDepth: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "AppTests\n" +
+		"\\-- App\n" +
+		"    |-- Core ...\n" +
+		"    \\-- Util"
+	if out != expected {
+		t.Fatalf("unexpected depth-limited output:\n%s", out)
+	}
+}
+
+func TestTerminalInvertedRendersDependentsBelowLeaves(t *testing.T) {
+	out, err := TerminalWithOptions(styledTerminalGraph(), TerminalOptions{Invert: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "Net\n" +
+		"\\-- Core\n" +
+		"    \\-- App\n" +
+		"        \\-- AppTests\n\n" +
+		"Util\n" +
+		"|-- App\n" +
+		"|   \\-- AppTests\n" +
+		"\\-- Core\n" +
+		"    \\-- App\n" +
+		"        \\-- AppTests"
+	if out != expected {
+		t.Fatalf("unexpected inverted output:\n%s", out)
+	}
+}
+
+func TestTerminalInvertedFallsBackToCycleRootsAndMarksRepeats(t *testing.T) {
+	g := graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::A": {ID: "target::A", Label: "A", Kind: graph.NodeKindTarget},
+			"target::B": {ID: "target::B", Label: "B", Kind: graph.NodeKindTarget},
+			"target::C": {ID: "target::C", Label: "C", Kind: graph.NodeKindTarget},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::A", ToID: "target::B", Kind: graph.EdgeKindTarget},
+			{FromID: "target::B", ToID: "target::A", Kind: graph.EdgeKindTarget},
+			{FromID: "target::C", ToID: "target::A", Kind: graph.EdgeKindTarget},
+		},
+	}
+
+	out, err := TerminalWithOptions(g, TerminalOptions{Invert: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "A\n" +
+		"|-- B\n" +
+		"|   \\-- A (*)\n" +
+		"\\-- C"
+	if out != expected {
+		t.Fatalf("unexpected inverted cycle output:\n%s", out)
+	}
+}
@@ -0,0 +1,474 @@
+(function () {
+  "use strict";
+
+  var SVG_NS = "http://www.w3.org/2000/svg";
+  var NODE_WIDTH = 180;
+  var NODE_HEIGHT = 32;
+  var GAP_X = 24;
+  var GAP_Y = 72;
+  var MAX_LABEL = 26;
+
+  var data = JSON.parse(document.getElementById("graph-data").textContent);
+  var nodesByID = {};
+  data.nodes.forEach(function (node) {
+    nodesByID[node.id] = node;
+  });
+
+  var svg = document.getElementById("canvas");
+  var viewport = document.getElementById("viewport");
+  var edgeLayer = document.getElementById("edges");
+  var nodeLayer = document.getElementById("nodes");
+  var details = document.getElementById("details");
+  var search = document.getElementById("search");
+  var matchCount = document.getElementById("match-count");
+  var stats = document.getElementById("stats");
+  var toggleExternal = document.getElementById("toggle-external");
+  var toggleTests = document.getElementById("toggle-tests");
+
+  var view = { scale: 1, x: 20, y: 20 };
+  var state = { selected: null, query: "" };
+  var current = null;
+
+  function isVisible(node) {
+    if (!toggleExternal.checked && node.kind === "external_product") {
+      return false;
+    }
+    if (!toggleTests.checked && node.test) {
+      return false;
+    }
+    return true;
+  }
+
+  function visibleGraph() {
+    var ids = data.nodes.filter(isVisible).map(function (node) {
+      return node.id;
+    });
+    var shown = {};
+    ids.forEach(function (id) {
+      shown[id] = true;
+    });
+    var edges = data.edges.filter(function (edge) {
+      return shown[edge.from] && shown[edge.to];
+    });
+    var succ = {};
+    var pred = {};
+    ids.forEach(function (id) {
+      succ[id] = [];
+      pred[id] = [];
+    });
+    edges.forEach(function (edge) {
+      succ[edge.from].push(edge.to);
+      pred[edge.to].push(edge.from);
+    });
+    return { ids: ids, edges: edges, succ: succ, pred: pred };
+  }
+
+  // layout assigns every node to a layer (longest chain from the dependents above it)
+  // and orders each layer by the barycenter of its neighbours to reduce crossings.
+  function layout(g) {
+    var back = {};
+    var mark = {};
+    function visit(id) {
+      mark[id] = 1;
+      g.succ[id].forEach(function (next) {
+        if (mark[next] === 1) {
+          back[id + "\u0000" + next] = true;
+        } else if (!mark[next]) {
+          visit(next);
+        }
+      });
+      mark[id] = 2;
+    }
+    g.ids.forEach(function (id) {
+      if (!mark[id]) {
+        visit(id);
+      }
+    });
+
+    var forward = function (from, to) {
+      return from !== to && !back[from + "\u0000" + to];
+    };
+    var indegree = {};
+    g.ids.forEach(function (id) {
+      indegree[id] = 0;
+    });
+    g.edges.forEach(function (edge) {
+      if (forward(edge.from, edge.to)) {
+        indegree[edge.to]++;
+      }
+    });
+    var layer = {};
+    var queue = g.ids.filter(function (id) {
+      return indegree[id] === 0;
+    });
+    queue.forEach(function (id) {
+      layer[id] = 0;
+    });
+    while (queue.length > 0) {
+      var id = queue.shift();
+      g.succ[id].forEach(function (next) {
+        if (!forward(id, next)) {
+          return;
+        }
+        layer[next] = Math.max(layer[next] || 0, layer[id] + 1);
+        indegree[next]--;
+        if (indegree[next] === 0) {
+          queue.push(next);
+        }
+      });
+    }
+
+    var layers = [];
+    g.ids.forEach(function (id) {
+      var l = layer[id] || 0;
+      (layers[l] = layers[l] || []).push(id);
+    });
+    layers = layers.filter(Boolean);
+    layers.forEach(function (ids) {
+      ids.sort(function (a, b) {
+        return nodesByID[a].label.localeCompare(nodesByID[b].label);
+      });
+    });
+
+    var order = {};
+    function index() {
+      layers.forEach(function (ids) {
+        ids.forEach(function (id, i) {
+          order[id] = i;
+        });
+      });
+    }
+    function sweep(ids, neighbours) {
+      var center = {};
+      ids.forEach(function (id) {
+        var around = neighbours[id].filter(function (other) {
+          return order[other] !== undefined && other !== id;
+        });
+        if (around.length === 0) {
+          center[id] = order[id];
+          return;
+        }
+        center[id] = around.reduce(function (sum, other) {
+          return sum + order[other];
+        }, 0) / around.length;
+      });
+      ids.sort(function (a, b) {
+        return center[a] - center[b] || order[a] - order[b];
+      });
+      ids.forEach(function (id, i) {
+        order[id] = i;
+      });
+    }
+    index();
+    for (var pass = 0; pass < 4; pass++) {
+      for (var down = 1; down < layers.length; down++) {
+        sweep(layers[down], g.pred);
+      }
+      for (var up = layers.length - 2; up >= 0; up--) {
+        sweep(layers[up], g.succ);
+      }
+    }
+
+    var widest = layers.reduce(function (max, ids) {
+      return Math.max(max, ids.length);
+    }, 0);
+    var positions = {};
+    layers.forEach(function (ids, l) {
+      var offset = (widest - ids.length) * (NODE_WIDTH + GAP_X) / 2;
+      ids.forEach(function (id, i) {
+        positions[id] = { x: offset + i * (NODE_WIDTH + GAP_X), y: l * (NODE_HEIGHT + GAP_Y) };
+      });
+    });
+    return {
+      positions: positions,
+      width: Math.max(widest * (NODE_WIDTH + GAP_X) - GAP_X, 0),
+      height: Math.max(layers.length * (NODE_HEIGHT + GAP_Y) - GAP_Y, 0)
+    };
+  }
+
+  function element(name, attrs) {
+    var el = document.createElementNS(SVG_NS, name);
+    Object.keys(attrs || {}).forEach(function (key) {
+      el.setAttribute(key, attrs[key]);
+    });
+    return el;
+  }
+
+  function shortLabel(label) {
+    label = label.replace(/\n/g, " ");
+    return label.length > MAX_LABEL ? label.slice(0, MAX_LABEL - 1) + "…" : label;
+  }
+
+  function edgePath(from, to) {
+    var x1 = from.x + NODE_WIDTH / 2;
+    var y1 = from.y + NODE_HEIGHT;
+    var x2 = to.x + NODE_WIDTH / 2;
+    var y2 = to.y;
+    if (y2 <= y1) {
+      x1 = from.x + NODE_WIDTH;
+      y1 = from.y + NODE_HEIGHT / 2;
+      x2 = to.x + NODE_WIDTH;
+      y2 = to.y + NODE_HEIGHT / 2;
+      var bend = Math.max(x1, x2) + GAP_X + Math.abs(y1 - y2) / 4;
+      return "M" + x1 + "," + y1 + " C" + bend + "," + y1 + " " + bend + "," + y2 + " " + x2 + "," + y2;
+    }
+    var mid = (y1 + y2) / 2;
+    return "M" + x1 + "," + y1 + " C" + x1 + "," + mid + " " + x2 + "," + mid + " " + x2 + "," + y2;
+  }
+
+  function draw() {
+    var g = visibleGraph();
+    var placed = layout(g);
+    current = { graph: g, layout: placed, nodes: {}, edges: [] };
+
+    edgeLayer.textContent = "";
+    nodeLayer.textContent = "";
+    g.edges.forEach(function (edge) {
+      var path = element("path", {
+        "class": "edge " + edge.kind,
+        d: edgePath(placed.positions[edge.from], placed.positions[edge.to]),
+        "marker-end": "url(#arrow)"
+      });
+      if (edge.weight > 1) {
+        var title = element("title");
+        title.textContent = edge.weight + " dependencies";
+        path.appendChild(title);
+      }
+      edgeLayer.appendChild(path);
+      current.edges.push({ edge: edge, el: path });
+    });
+    g.ids.forEach(function (id) {
+      var node = nodesByID[id];
+      var pos = placed.positions[id];
+      var group = element("g", {
+        "class": "node " + node.kind + (node.test ? " test" : ""),
+        transform: "translate(" + pos.x + "," + pos.y + ")"
+      });
+      var title = element("title");
+      title.textContent = node.label;
+      group.appendChild(title);
+      group.appendChild(element("rect", { width: NODE_WIDTH, height: NODE_HEIGHT }));
+      var text = element("text", { x: NODE_WIDTH / 2, y: NODE_HEIGHT / 2, "text-anchor": "middle", "dominant-baseline": "central" });
+      text.textContent = shortLabel(node.label);
+      group.appendChild(text);
+      group.addEventListener("click", function (event) {
+        event.stopPropagation();
+        select(state.selected === id ? null : id);
+      });
+      nodeLayer.appendChild(group);
+      current.nodes[id] = group;
+    });
+
+    stats.textContent = g.ids.length + " nodes, " + g.edges.length + " edges";
+    if (state.selected && !current.nodes[state.selected]) {
+      state.selected = null;
+    }
+    highlight();
+  }
+
+  function reach(start, adjacency) {
+    var seen = {};
+    var stack = [start];
+    while (stack.length > 0) {
+      var id = stack.pop();
+      adjacency[id].forEach(function (next) {
+        if (!seen[next] && next !== start) {
+          seen[next] = true;
+          stack.push(next);
+        }
+      });
+    }
+    return seen;
+  }
+
+  function highlight() {
+    var selected = state.selected;
+    var query = state.query.toLowerCase();
+    var matches = 0;
+    var dependencies = selected ? reach(selected, current.graph.succ) : {};
+    var dependents = selected ? reach(selected, current.graph.pred) : {};
+
+    Object.keys(current.nodes).forEach(function (id) {
+      var el = current.nodes[id];
+      var match = query !== "" && nodesByID[id].label.toLowerCase().indexOf(query) >= 0;
+      if (match) {
+        matches++;
+      }
+      el.classList.toggle("match", match);
+      el.classList.toggle("selected", id === selected);
+      el.classList.toggle("dependency", !!dependencies[id]);
+      el.classList.toggle("dependent", !!dependents[id]);
+      el.classList.toggle("faded", !!selected && id !== selected && !dependencies[id] && !dependents[id]);
+    });
+    current.edges.forEach(function (item) {
+      var from = item.edge.from;
+      var to = item.edge.to;
+      var dependency = !!selected && (from === selected || dependencies[from]) && !!dependencies[to];
+      var dependent = !!selected && (to === selected || dependents[to]) && !!dependents[from];
+      item.el.classList.toggle("dependency", dependency);
+      item.el.classList.toggle("dependent", dependent);
+      item.el.classList.toggle("faded", !!selected && !dependency && !dependent);
+    });
+    matchCount.textContent = query === "" ? "" : matches + (matches === 1 ? " match" : " matches");
+    showDetails(selected, dependencies, dependents);
+  }
+
+  function listItems(title, ids) {
+    var fragment = document.createDocumentFragment();
+    var heading = document.createElement("h3");
+    heading.textContent = title + " (" + ids.length + ")";
+    fragment.appendChild(heading);
+    var list = document.createElement("ul");
+    ids.sort(function (a, b) {
+      return nodesByID[a].label.localeCompare(nodesByID[b].label);
+    }).forEach(function (id) {
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.textContent = nodesByID[id].label;
+      link.addEventListener("click", function () {
+        select(id);
+        center(id);
+      });
+      item.appendChild(link);
+      list.appendChild(item);
+    });
+    fragment.appendChild(list);
+    return fragment;
+  }
+
+  function showDetails(id, dependencies, dependents) {
+    details.textContent = "";
+    details.hidden = !id;
+    if (!id) {
+      return;
+    }
+    var node = nodesByID[id];
+    var heading = document.createElement("h2");
+    heading.textContent = node.label;
+    details.appendChild(heading);
+    var fields = document.createElement("dl");
+    [["ID", node.id], ["Kind", node.kind + (node.test ? " (test)" : "")], ["Type", node.type], ["Native type", node.native_type], ["Package", node.package], ["Project", node.project], ["Path", node.path]].forEach(function (field) {
+      if (!field[1]) {
+        return;
+      }
+      var term = document.createElement("dt");
+      term.textContent = field[0];
+      var value = document.createElement("dd");
+      value.textContent = field[1];
+      fields.appendChild(term);
+      fields.appendChild(value);
+    });
+    details.appendChild(fields);
+    details.appendChild(listItems("Direct dependencies", current.graph.succ[id].slice()));
+    details.appendChild(listItems("Direct dependents", current.graph.pred[id].slice()));
+    var summary = document.createElement("p");
+    summary.className = "muted";
+    summary.textContent = Object.keys(dependencies).length + " transitive dependencies, " + Object.keys(dependents).length + " transitive dependents";
+    details.appendChild(summary);
+  }
+
+  function select(id) {
+    state.selected = id;
+    highlight();
+  }
+
+  function applyView() {
+    viewport.setAttribute("transform", "translate(" + view.x + "," + view.y + ") scale(" + view.scale + ")");
+  }
+
+  function fit() {
+    var box = svg.getBoundingClientRect();
+    var width = current.layout.width + 40;
+    var height = current.layout.height + 40;
+    view.scale = Math.min(2, box.width / width, box.height / height) || 1;
+    view.x = (box.width - current.layout.width * view.scale) / 2;
+    view.y = 20 * view.scale;
+    applyView();
+  }
+
+  function center(id) {
+    var pos = current.layout.positions[id];
+    if (!pos) {
+      return;
+    }
+    var box = svg.getBoundingClientRect();
+    view.x = box.width / 2 - (pos.x + NODE_WIDTH / 2) * view.scale;
+    view.y = box.height / 2 - (pos.y + NODE_HEIGHT / 2) * view.scale;
+    applyView();
+  }
+
+  var drag = null;
+  svg.addEventListener("mousedown", function (event) {
+    drag = { x: event.clientX - view.x, y: event.clientY - view.y, moved: false };
+    svg.classList.add("dragging");
+  });
+  window.addEventListener("mousemove", function (event) {
+    if (!drag) {
+      return;
+    }
+    drag.moved = true;
+    view.x = event.clientX - drag.x;
+    view.y = event.clientY - drag.y;
+    applyView();
+  });
+  window.addEventListener("mouseup", function () {
+    svg.classList.remove("dragging");
+    setTimeout(function () {
+      drag = null;
+    }, 0);
+  });
+  svg.addEventListener("click", function () {
+    if (drag && drag.moved) {
+      return;
+    }
+    select(null);
+  });
+  svg.addEventListener("wheel", function (event) {
+    event.preventDefault();
+    var box = svg.getBoundingClientRect();
+    var mx = event.clientX - box.left;
+    var my = event.clientY - box.top;
+    var scale = Math.min(4, Math.max(0.05, view.scale * Math.exp(-event.deltaY * 0.0015)));
+    view.x = mx - (mx - view.x) * (scale / view.scale);
+    view.y = my - (my - view.y) * (scale / view.scale);
+    view.scale = scale;
+    applyView();
+  }, { passive: false });
+
+  search.addEventListener("input", function () {
+    state.query = search.value.trim();
+    highlight();
+  });
+  search.addEventListener("keydown", function (event) {
+    if (event.key !== "Enter" || state.query === "") {
+      return;
+    }
+    var query = state.query.toLowerCase();
+    var found = current.graph.ids.filter(function (id) {
+      return nodesByID[id].label.toLowerCase().indexOf(query) >= 0;
+    });
+    if (found.length > 0) {
+      select(found[0]);
+      center(found[0]);
+    }
+  });
+  toggleExternal.addEventListener("change", function () {
+    draw();
+    fit();
+  });
+  toggleTests.addEventListener("change", function () {
+    draw();
+    fit();
+  });
+  document.getElementById("fit").addEventListener("click", fit);
+
+  if (!data.nodes.some(function (node) { return node.test; })) {
+    toggleTests.parentNode.hidden = true;
+  }
+  if (!data.nodes.some(function (node) { return node.kind === "external_product"; })) {
+    toggleExternal.parentNode.hidden = true;
+  }
+
+  draw();
+  fit();
+})();
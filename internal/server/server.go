@@ -0,0 +1,253 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+	"swift-deps-diagram/internal/pathquery"
+)
+
+// Renderer renders g in a --format value.
+type Renderer func(ctx context.Context, g graph.Graph, format string) ([]byte, error)
+
+type endpoint struct {
+	format      string
+	contentType string
+}
+
+// endpoints maps request paths to the format they render.
+var endpoints = map[string]endpoint{
+	"/":              {"html", "text/html; charset=utf-8"},
+	"/graph.json":    {"json", "application/json"},
+	"/graph.dot":     {"dot", "text/vnd.graphviz; charset=utf-8"},
+	"/graph.mmd":     {"mermaid", "text/plain; charset=utf-8"},
+	"/graph.puml":    {"plantuml", "text/plain; charset=utf-8"},
+	"/graph.d2":      {"d2", "text/plain; charset=utf-8"},
+	"/graph.txt":     {"terminal", "text/plain; charset=utf-8"},
+	"/graph.md":      {"markdown", "text/markdown; charset=utf-8"},
+	"/graph.graphml": {"graphml", "application/xml"},
+	"/graph.gexf":    {"gexf", "application/xml"},
+	"/graph.svg":     {"svg", "image/svg+xml"},
+	"/graph.png":     {"png", "image/png"},
+	"/graph.pdf":     {"pdf", "application/pdf"},
+}
+
+// reloadScript makes the viewer page reload when the served graph changes.
+const reloadScript = `<script>
+(function () {
+  var version = null;
+  function poll() {
+    fetch("/version", {cache: "no-store"}).then(function (r) { return r.json(); }).then(function (v) {
+      if (version !== null && v.version !== version) { location.reload(); return; }
+      version = v.version;
+    }).catch(function () {}).then(function () { setTimeout(poll, 2000); });
+  }
+  poll();
+})();
+</script>
+`
+
+// View selects the part of the graph one request shows.
+type View struct {
+	// Focus is a node ID or unique label; when set only its neighborhood is shown.
+	Focus string
+	// Depth limits the neighborhood to this many hops; zero means unlimited.
+	Depth int
+	// HideExternal, CollapsePackages, and HideTests apply the matching graph transforms.
+	HideExternal     bool
+	CollapsePackages bool
+	HideTests        bool
+}
+
+func parseBool(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("%s must be a boolean", name), err)
+	}
+	return parsed, nil
+}
+
+// ParseView reads the focus, depth, hide-external, collapse-packages, and hide-tests
+// query parameters.
+func ParseView(query url.Values) (View, error) {
+	view := View{Focus: query.Get("focus")}
+	if value := query.Get("depth"); value != "" {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			return View{}, apperrors.New(apperrors.KindInvalidArgs, "depth must be a non-negative integer", err)
+		}
+		view.Depth = depth
+	}
+	var err error
+	if view.HideExternal, err = parseBool(query, "hide-external"); err != nil {
+		return View{}, err
+	}
+	if view.CollapsePackages, err = parseBool(query, "collapse-packages"); err != nil {
+		return View{}, err
+	}
+	if view.HideTests, err = parseBool(query, "hide-tests"); err != nil {
+		return View{}, err
+	}
+	return view, nil
+}
+
+// Apply returns the part of g selected by the view. Filters run before the focus
+// neighborhood is taken, so hidden nodes never connect the focus to other nodes.
+func (v View) Apply(g graph.Graph) (graph.Graph, error) {
+	if v.HideTests {
+		g = graph.HideTests(g)
+	}
+	if v.HideExternal {
+		g = graph.HideExternal(g)
+	}
+	if v.CollapsePackages {
+		g = graph.CollapsePackages(g)
+	}
+	if v.Focus != "" {
+		id, err := pathquery.ResolveNode(g, v.Focus)
+		if err != nil {
+			return graph.Graph{}, err
+		}
+		g = graph.Neighborhood(g, id, v.Depth)
+	}
+	return g, nil
+}
+
+// Server serves the current dependency graph over HTTP. Update replaces the graph while
+// requests are being served.
+type Server struct {
+	render Renderer
+
+	mu      sync.RWMutex
+	g       graph.Graph
+	loaded  bool
+	version int
+	lastErr error
+}
+
+// New returns a server that renders graphs with render and has no graph yet.
+func New(render Renderer) *Server {
+	return &Server{render: render}
+}
+
+// Update replaces the served graph and clears the last error.
+func (s *Server) Update(g graph.Graph) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.g = g
+	s.loaded = true
+	s.version++
+	s.lastErr = nil
+}
+
+// Fail records a failed recomputation; the last good graph keeps being served.
+func (s *Server) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+}
+
+func (s *Server) snapshot() (graph.Graph, bool, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.g, s.loaded, s.version, s.lastErr
+}
+
+// withReloadScript inserts reloadScript before the closing body tag of page.
+func withReloadScript(page []byte) []byte {
+	html := string(page)
+	at := strings.LastIndex(html, "</body>")
+	if at < 0 {
+		return []byte(html + reloadScript)
+	}
+	return []byte(html[:at] + reloadScript + html[at:])
+}
+
+func statusFor(err error) int {
+	switch {
+	case apperrors.IsKind(err, apperrors.KindInvalidArgs):
+		return http.StatusBadRequest
+	case apperrors.IsKind(err, apperrors.KindGraphvizNotFound):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ServeHTTP serves the viewer page at /, the graph in every format at /graph.<ext>, and
+// {"version", "error"} at /version for change polling.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	g, loaded, version, lastErr := s.snapshot()
+
+	if r.URL.Path == "/version" {
+		status := struct {
+			Version int    `json:"version"`
+			Error   string `json:"error,omitempty"`
+		}{Version: version}
+		if lastErr != nil {
+			status.Error = lastErr.Error()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(status)
+		return
+	}
+
+	target, ok := endpoints[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !loaded {
+		message := "dependency graph is not loaded yet"
+		if lastErr != nil {
+			message = lastErr.Error()
+		}
+		http.Error(w, message, http.StatusServiceUnavailable)
+		return
+	}
+
+	view, err := ParseView(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+	selected, err := view.Apply(g)
+	if err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+	body, err := s.render(r.Context(), selected, target.format)
+	if err != nil {
+		http.Error(w, err.Error(), statusFor(err))
+		return
+	}
+	if target.format == "html" {
+		body = withReloadScript(body)
+	}
+
+	w.Header().Set("Content-Type", target.contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Graph-Version", strconv.Itoa(version))
+	if lastErr != nil {
+		w.Header().Set("X-Graph-Error", strings.ReplaceAll(lastErr.Error(), "\n", " "))
+	}
+	_, _ = w.Write(body)
+}
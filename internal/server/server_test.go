@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func serverGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":       {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget},
+			"target::Feature":   {ID: "target::Feature", Label: "Feature", Kind: graph.NodeKindTarget},
+			"target::Core":      {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget},
+			"target::CoreTests": {ID: "target::CoreTests", Label: "CoreTests", Kind: graph.NodeKindTarget, Test: true},
+			"pkg::nio::NIO":     {ID: "pkg::nio::NIO", Label: "NIO", Kind: graph.NodeKindExternalProduct, Package: "nio"},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Feature", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Feature", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::CoreTests", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::nio::NIO", Kind: graph.EdgeKindProduct},
+		},
+	}
+}
+
+// listRenderer renders the format followed by the sorted node IDs.
+func listRenderer(_ context.Context, g graph.Graph, format string) ([]byte, error) {
+	if format == "svg" {
+		return nil, apperrors.New(apperrors.KindGraphvizNotFound, "graphviz 'dot' binary not found in PATH", nil)
+	}
+	body := format + ":" + strings.Join(graph.SortedNodeIDs(g), ",")
+	if format == "html" {
+		body = "<html><body>" + body + "</body></html>"
+	}
+	return []byte(body), nil
+}
+
+func get(t *testing.T, h http.Handler, target string) (*http.Response, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	resp := rec.Result()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestServerRendersEndpointsWithViewParameters(t *testing.T) {
+	s := New(listRenderer)
+	s.Update(serverGraph())
+
+	cases := map[string]string{
+		"/graph.dot": "dot:pkg::nio::NIO,target::App,target::Core,target::CoreTests,target::Feature",
+		"/graph.mmd?hide-external=1&hide-tests=1": "mermaid:target::App,target::Core,target::Feature",
+		"/graph.json?focus=Feature&depth=1":       "json:target::App,target::Core,target::Feature",
+		"/graph.txt?focus=target::Core":           "terminal:pkg::nio::NIO,target::App,target::Core,target::CoreTests,target::Feature",
+		"/graph.d2?collapse-packages=true":        "d2:pkg::nio,target::App,target::Core,target::CoreTests,target::Feature",
+	}
+	for target, want := range cases {
+		resp, body := get(t, s, target)
+		if resp.StatusCode != http.StatusOK || body != want {
+			t.Fatalf("GET %s: status %d body %q, want %q", target, resp.StatusCode, body, want)
+		}
+	}
+
+	resp, _ := get(t, s, "/graph.json")
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+}
+
+func TestServerViewerPagePollsForChanges(t *testing.T) {
+	s := New(listRenderer)
+	s.Update(serverGraph())
+
+	resp, body := get(t, s, "/?hide-tests=1")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected viewer response %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(body, "html:pkg::nio::NIO,target::App,target::Core,target::Feature") || !strings.Contains(body, `fetch("/version"`) {
+		t.Fatalf("unexpected viewer page:\n%s", body)
+	}
+	if strings.Index(body, "/version") > strings.Index(body, "</body>") {
+		t.Fatalf("expected reload script inside the body:\n%s", body)
+	}
+}
+
+func TestServerReportsVersionsAndKeepsLastGoodGraph(t *testing.T) {
+	s := New(listRenderer)
+
+	resp, _ := get(t, s, "/graph.dot")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before the first graph, got %d", resp.StatusCode)
+	}
+
+	s.Update(serverGraph())
+	s.Fail(apperrors.New(apperrors.KindManifestDecode, "broken manifest", nil))
+
+	resp, body := get(t, s, "/graph.dot?hide-external=1&hide-tests=1")
+	if resp.StatusCode != http.StatusOK || body != "dot:target::App,target::Core,target::Feature" {
+		t.Fatalf("expected last good graph, got %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("X-Graph-Error") != "broken manifest" || resp.Header.Get("X-Graph-Version") != "1" {
+		t.Fatalf("unexpected headers %v", resp.Header)
+	}
+
+	_, body = get(t, s, "/version")
+	var status struct {
+		Version int    `json:"version"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(body), &status); err != nil || status.Version != 1 || status.Error != "broken manifest" {
+		t.Fatalf("unexpected version payload %q (%v)", body, err)
+	}
+
+	s.Update(graph.Graph{Nodes: map[string]graph.Node{}})
+	_, body = get(t, s, "/version")
+	if body != "{\"version\":2}\n" {
+		t.Fatalf("expected bumped version without error, got %q", body)
+	}
+}
+
+func TestServerRejectsBadRequests(t *testing.T) {
+	s := New(listRenderer)
+	s.Update(serverGraph())
+
+	cases := map[string]int{
+		"/graph.dot?focus=Missing":       http.StatusBadRequest,
+		"/graph.dot?depth=-1":            http.StatusBadRequest,
+		"/graph.dot?hide-external=maybe": http.StatusBadRequest,
+		"/graph.bmp":                     http.StatusNotFound,
+		"/graph.svg":                     http.StatusNotImplemented,
+	}
+	for target, want := range cases {
+		if resp, body := get(t, s, target); resp.StatusCode != want {
+			t.Fatalf("GET %s: expected %d, got %d (%s)", target, want, resp.StatusCode, body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graph.dot", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestServerWorksOverHTTP(t *testing.T) {
+	s := New(listRenderer)
+	s.Update(serverGraph())
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/graph.mmd?focus=App&depth=1")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "mermaid:target::App,target::Feature" {
+		t.Fatalf("unexpected body %q", body)
+	}
+}
@@ -0,0 +1,243 @@
+package structurizr
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/filter"
+	"swift-deps-diagram/internal/graph"
+)
+
+// DefaultContainer holds local targets that no mapping rule assigns to a container.
+const DefaultContainer = "Targets"
+
+// Mapping assigns local targets to C4 containers.
+type Mapping struct {
+	// System names the software system that owns the local containers.
+	System     string      `json:"system"`
+	Containers []Container `json:"containers"`
+}
+
+// Container lists the targets that belong to one C4 container. Targets are label
+// patterns with the --include/--exclude syntax (globs whose * and ? also match /, or
+// regex:<expr>); the first matching container wins.
+type Container struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Technology  string   `json:"technology"`
+	Targets     []string `json:"targets"`
+}
+
+// LoadMapping reads and validates a container mapping JSON file.
+func LoadMapping(mappingPath string) (Mapping, error) {
+	data, err := os.ReadFile(mappingPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Mapping{}, apperrors.New(apperrors.KindInputNotFound, fmt.Sprintf("structurizr mapping not found at %s", mappingPath), err)
+		}
+		return Mapping{}, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading structurizr mapping %s", mappingPath), err)
+	}
+	var m Mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Mapping{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("failed to decode structurizr mapping %s", mappingPath), err)
+	}
+	seen := make(map[string]struct{}, len(m.Containers))
+	for _, container := range m.Containers {
+		if container.Name == "" {
+			return Mapping{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("structurizr mapping %s has a container without a name", mappingPath), nil)
+		}
+		if _, ok := seen[container.Name]; ok {
+			return Mapping{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("structurizr mapping %s declares container %q twice", mappingPath, container.Name), nil)
+		}
+		seen[container.Name] = struct{}{}
+		for _, pattern := range container.Targets {
+			if _, err := filter.Compile(pattern); err != nil {
+				return Mapping{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("invalid target pattern %q in structurizr mapping %s", pattern, mappingPath), err)
+			}
+		}
+	}
+	return m, nil
+}
+
+// containerFor compiles the container patterns once and returns a function giving the
+// index of the first container whose patterns match a label, or -1. Invalid patterns,
+// already rejected by LoadMapping, never match.
+func (m Mapping) containerFor() func(label string) int {
+	patterns := make([][]*regexp.Regexp, len(m.Containers))
+	for i, container := range m.Containers {
+		for _, pattern := range container.Targets {
+			if re, err := filter.Compile(pattern); err == nil {
+				patterns[i] = append(patterns[i], re)
+			}
+		}
+	}
+	return func(label string) int {
+		for i, res := range patterns {
+			for _, re := range res {
+				if re.MatchString(label) {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return "\"" + s + "\""
+}
+
+type localContainer struct {
+	Container
+	ID      string
+	NodeIDs []string
+}
+
+type externalSystem struct {
+	Name string
+	ID   string
+	// Collapsed is set when the system itself stands for a --collapse-packages node.
+	Collapsed string
+	NodeIDs   []string
+}
+
+// DSL renders g as a Structurizr DSL workspace. Local targets become components in the
+// containers assigned by m, external packages become software systems with one container
+// per product, and every edge becomes a relationship tagged with its edge kind.
+func DSL(g graph.Graph, m Mapping, systemName string) (string, error) {
+	if m.System != "" {
+		systemName = m.System
+	}
+
+	containers := make([]*localContainer, 0, len(m.Containers)+1)
+	for i, container := range m.Containers {
+		containers = append(containers, &localContainer{Container: container, ID: fmt.Sprintf("container%d", i+1)})
+	}
+	var fallback *localContainer
+	containerFor := m.containerFor()
+	systems := make(map[string]*externalSystem)
+	elementIDs := make(map[string]string, len(g.Nodes))
+
+	componentCount := 0
+	productCount := 0
+	for _, id := range graph.SortedNodeIDs(g) {
+		node := g.Nodes[id]
+		if node.Kind == graph.NodeKindExternalProduct {
+			name := node.Package
+			if name == "" {
+				name = node.Label
+			}
+			system, ok := systems[name]
+			if !ok {
+				system = &externalSystem{Name: name}
+				systems[name] = system
+			}
+			if node.ID == graph.PackageNodeID(node.Package) {
+				system.Collapsed = id
+				continue
+			}
+			productCount++
+			elementIDs[id] = fmt.Sprintf("product%d", productCount)
+			system.NodeIDs = append(system.NodeIDs, id)
+			continue
+		}
+
+		componentCount++
+		elementIDs[id] = fmt.Sprintf("component%d", componentCount)
+		index := containerFor(node.Label)
+		if index < 0 {
+			if fallback == nil {
+				fallback = &localContainer{Container: Container{Name: DefaultContainer}, ID: fmt.Sprintf("container%d", len(m.Containers)+1)}
+			}
+			fallback.NodeIDs = append(fallback.NodeIDs, id)
+			continue
+		}
+		containers[index].NodeIDs = append(containers[index].NodeIDs, id)
+	}
+	if fallback != nil {
+		containers = append(containers, fallback)
+	}
+
+	systemNames := make([]string, 0, len(systems))
+	for name := range systems {
+		systemNames = append(systemNames, name)
+	}
+	sort.Strings(systemNames)
+	for i, name := range systemNames {
+		system := systems[name]
+		system.ID = fmt.Sprintf("external%d", i+1)
+		if system.Collapsed != "" {
+			elementIDs[system.Collapsed] = system.ID
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("workspace %s %s {\n", quote(systemName), quote("Generated by swift-deps-diagram from the build graph.")))
+	b.WriteString("    model {\n")
+	b.WriteString(fmt.Sprintf("        localSystem = softwareSystem %s {\n", quote(systemName)))
+	for _, container := range containers {
+		b.WriteString(fmt.Sprintf("            %s = container %s %s %s {\n", container.ID, quote(container.Name), quote(container.Description), quote(container.Technology)))
+		for _, id := range container.NodeIDs {
+			node := g.Nodes[id]
+			b.WriteString(fmt.Sprintf("                %s = component %s %s %s %s\n", elementIDs[id], quote(node.Label), quote(""), quote(node.NativeType), quote(string(node.Kind))))
+		}
+		b.WriteString("            }\n")
+	}
+	b.WriteString("        }\n")
+
+	for _, name := range systemNames {
+		system := systems[name]
+		if len(system.NodeIDs) == 0 {
+			b.WriteString(fmt.Sprintf("        %s = softwareSystem %s %s %s\n", system.ID, quote(system.Name), quote(""), quote("External")))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("        %s = softwareSystem %s %s %s {\n", system.ID, quote(system.Name), quote(""), quote("External")))
+		for _, id := range system.NodeIDs {
+			b.WriteString(fmt.Sprintf("            %s = container %s %s %s %s\n", elementIDs[id], quote(g.Nodes[id].Label), quote(""), quote(""), quote("External")))
+		}
+		b.WriteString("        }\n")
+	}
+
+	for _, edge := range graph.SortedEdges(g) {
+		fromID, ok := elementIDs[edge.FromID]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown from node", nil)
+		}
+		toID, ok := elementIDs[edge.ToID]
+		if !ok {
+			return "", apperrors.New(apperrors.KindRuntime, "graph edge references unknown to node", nil)
+		}
+		description := "depends on"
+		if edge.Weight > 1 {
+			description = fmt.Sprintf("depends on %d products", edge.Weight)
+		}
+		b.WriteString(fmt.Sprintf("        %s -> %s %s %s %s\n", fromID, toID, quote(description), quote(""), quote(string(edge.Kind))))
+	}
+	b.WriteString("    }\n\n")
+
+	b.WriteString("    views {\n")
+	b.WriteString("        systemLandscape \"Landscape\" {\n            include *\n            autoLayout\n        }\n")
+	b.WriteString("        container localSystem \"Containers\" {\n            include *\n            autoLayout\n        }\n")
+	for i, container := range containers {
+		if len(container.NodeIDs) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("        component %s \"Components%d\" {\n            include *\n            autoLayout\n        }\n", container.ID, i+1))
+	}
+	b.WriteString("        styles {\n")
+	b.WriteString("            element \"External\" {\n                background #999999\n                color #ffffff\n            }\n")
+	b.WriteString(fmt.Sprintf("            relationship %s {\n                style dashed\n            }\n", quote(string(graph.EdgeKindProduct))))
+	b.WriteString(fmt.Sprintf("            relationship %s {\n                style dotted\n            }\n", quote(string(graph.EdgeKindByName))))
+	b.WriteString("        }\n")
+	b.WriteString("    }\n")
+	b.WriteString("}")
+	return b.String(), nil
+}
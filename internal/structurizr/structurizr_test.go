@@ -0,0 +1,139 @@
+package structurizr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func sampleGraph() graph.Graph {
+	return graph.Graph{
+		Nodes: map[string]graph.Node{
+			"target::App":       {ID: "target::App", Label: "App", Kind: graph.NodeKindTarget, NativeType: "executable"},
+			"target::Core":      {ID: "target::Core", Label: "Core", Kind: graph.NodeKindTarget, NativeType: "regular"},
+			"pkg::nio::NIO":     {ID: "pkg::nio::NIO", Label: "NIO", Kind: graph.NodeKindExternalProduct, Package: "nio"},
+			"pkg::nio::NIOHTTP": {ID: "pkg::nio::NIOHTTP", Label: "NIOHTTP", Kind: graph.NodeKindExternalProduct, Package: "nio"},
+			"pkg::log":          {ID: "pkg::log", Label: "log", Kind: graph.NodeKindExternalProduct, Package: "log"},
+		},
+		Edges: []graph.Edge{
+			{FromID: "target::App", ToID: "target::Core", Kind: graph.EdgeKindTarget},
+			{FromID: "target::Core", ToID: "pkg::nio::NIO", Kind: graph.EdgeKindProduct},
+			{FromID: "target::Core", ToID: "pkg::log", Kind: graph.EdgeKindProduct, Weight: 2},
+			{FromID: "target::App", ToID: "pkg::nio::NIOHTTP", Kind: graph.EdgeKindByName},
+		},
+	}
+}
+
+func TestDSLWithoutMappingUsesDefaultContainer(t *testing.T) {
+	out, err := DSL(sampleGraph(), Mapping{}, "Sample")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`workspace "Sample" "Generated by swift-deps-diagram from the build graph." {`,
+		`        localSystem = softwareSystem "Sample" {`,
+		`            container1 = container "Targets" "" "" {`,
+		`                component1 = component "App" "" "executable" "target"`,
+		`                component2 = component "Core" "" "regular" "target"`,
+		`        external1 = softwareSystem "log" "" "External"`,
+		"        external2 = softwareSystem \"nio\" \"\" \"External\" {\n            product1 = container \"NIO\" \"\" \"\" \"External\"\n            product2 = container \"NIOHTTP\" \"\" \"\" \"External\"\n        }",
+		`        component1 -> component2 "depends on" "" "target"`,
+		`        component1 -> product2 "depends on" "" "by_name"`,
+		`        component2 -> external1 "depends on 2 products" "" "product"`,
+		`        component container1 "Components1" {`,
+		`            relationship "by_name" {`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+}
+
+func TestDSLAssignsTargetsFromMapping(t *testing.T) {
+	m := Mapping{
+		System: "iOS Platform",
+		Containers: []Container{
+			{Name: "Shell", Technology: "Swift", Targets: []string{"App"}},
+			{Name: "Unused"},
+		},
+	}
+	out, err := DSL(sampleGraph(), m, "Sample")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		`localSystem = softwareSystem "iOS Platform" {`,
+		"            container1 = container \"Shell\" \"\" \"Swift\" {\n                component1 = component \"App\"",
+		"            container3 = container \"Targets\" \"\" \"\" {\n                component2 = component \"Core\"",
+		`component container3 "Components3" {`,
+	} {
+		if !strings.Contains(out, part) {
+			t.Fatalf("missing %q in:\n%s", part, out)
+		}
+	}
+	if strings.Contains(out, `component container2 `) {
+		t.Fatalf("expected no component view for empty container:\n%s", out)
+	}
+}
+
+func TestQuoteEscapesStrings(t *testing.T) {
+	if got := quote("a\"b\\c\nd"); got != `"a\"b\\c d"` {
+		t.Fatalf("unexpected quoting: %s", got)
+	}
+}
+
+func TestMappingGlobsMatchBazelLabels(t *testing.T) {
+	m := Mapping{Containers: []Container{
+		{Name: "App", Targets: []string{"//app/*"}},
+		{Name: "Libs", Targets: []string{"regex:^//libs/.+:core$"}},
+	}}
+	containerFor := m.containerFor()
+	if got := containerFor("//app/feature:lib"); got != 0 {
+		t.Fatalf("expected //app/* to match across /, got %d", got)
+	}
+	if got := containerFor("//libs/net:core"); got != 1 {
+		t.Fatalf("expected regex pattern to match, got %d", got)
+	}
+	if got := containerFor("//tools:gen"); got != -1 {
+		t.Fatalf("expected no container, got %d", got)
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "mapping.json")
+	if err := os.WriteFile(valid, []byte(`{"system":"Platform","containers":[{"name":"Features","targets":["Feature*"]}]}`), 0o644); err != nil {
+		t.Fatalf("write mapping: %v", err)
+	}
+	m, err := LoadMapping(valid)
+	if err != nil {
+		t.Fatalf("unexpected load error: %v", err)
+	}
+	containerFor := m.containerFor()
+	if m.System != "Platform" || containerFor("FeatureLogin") != 0 || containerFor("Core") != -1 {
+		t.Fatalf("unexpected mapping: %#v", m)
+	}
+
+	if _, err := LoadMapping(filepath.Join(dir, "missing.json")); !apperrors.IsKind(err, apperrors.KindInputNotFound) {
+		t.Fatalf("expected input not found, got %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"bad-json.json":  `{`,
+		"no-name.json":   `{"containers":[{"targets":["A"]}]}`,
+		"duplicate.json": `{"containers":[{"name":"A"},{"name":"A"}]}`,
+		"pattern.json":   `{"containers":[{"name":"A","targets":["["]}]}`,
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatalf("write mapping: %v", err)
+		}
+		if _, err := LoadMapping(file); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("%s: expected invalid args, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+// Attributes are Graphviz attributes keyed by name.
+type Attributes map[string]string
+
+// Theme controls the Graphviz attributes written by the DOT renderer. Later layers
+// override earlier ones: Node, then NodeKinds, then TargetTypes, then the matching
+// Labels rules in order.
+type Theme struct {
+	// Extends names a preset the theme is layered on top of.
+	Extends string `json:"extends,omitempty"`
+	// Graph holds graph attributes such as rankdir, splines, concentrate, or ranksep.
+	Graph Attributes `json:"graph,omitempty"`
+	// Node and Edge hold defaults written as `node [...]` and `edge [...]` statements.
+	Node Attributes `json:"node,omitempty"`
+	Edge Attributes `json:"edge,omitempty"`
+	// NodeKinds and EdgeKinds style nodes and edges per graph.NodeKind and graph.EdgeKind.
+	NodeKinds map[string]Attributes `json:"node_kinds,omitempty"`
+	EdgeKinds map[string]Attributes `json:"edge_kinds,omitempty"`
+	// TargetTypes style target nodes per graph.TargetType.
+	TargetTypes map[string]Attributes `json:"target_types,omitempty"`
+	// Labels style nodes whose label matches a regular expression.
+	Labels []LabelRule `json:"labels,omitempty"`
+}
+
+// LabelRule applies Attributes to nodes whose label matches Pattern.
+type LabelRule struct {
+	Pattern    string     `json:"pattern"`
+	Attributes Attributes `json:"attributes"`
+
+	re *regexp.Regexp
+}
+
+// PresetUsage lists the built-in presets for help and error text.
+const PresetUsage = "light|dark|print"
+
+// Default reproduces the renderer's historical styling: top-to-bottom layout, boxed
+// targets, and dashed ellipses for external products. Target types other than
+// libraries get their own shapes.
+func Default() Theme {
+	return Theme{
+		Graph: Attributes{"rankdir": "TB"},
+		NodeKinds: map[string]Attributes{
+			string(graph.NodeKindTarget):          {"shape": "box"},
+			string(graph.NodeKindExternalProduct): {"shape": "ellipse", "style": "dashed"},
+		},
+		TargetTypes: map[string]Attributes{
+			string(graph.TargetTypeApp):           {"shape": "box3d"},
+			string(graph.TargetTypeAppExtension):  {"shape": "component"},
+			string(graph.TargetTypeFramework):     {"shape": "folder"},
+			string(graph.TargetTypeExecutable):    {"shape": "cds"},
+			string(graph.TargetTypeTest):          {"shape": "note"},
+			string(graph.TargetTypeUITest):        {"shape": "note"},
+			string(graph.TargetTypeMacro):         {"shape": "hexagon"},
+			string(graph.TargetTypePlugin):        {"shape": "parallelogram"},
+			string(graph.TargetTypeBinary):        {"shape": "box3d", "peripheries": "2"},
+			string(graph.TargetTypeSystemLibrary): {"shape": "cylinder"},
+			string(graph.TargetTypeAggregate):     {"shape": "octagon"},
+		},
+	}
+}
+
+var presets = map[string]Theme{
+	"light": {
+		Graph: Attributes{"rankdir": "TB", "splines": "true", "ranksep": "0.6", "fontname": "Helvetica", "bgcolor": "white"},
+		Node:  Attributes{"fontname": "Helvetica", "fontsize": "11", "color": "#4c78a8", "fontcolor": "#1f2328"},
+		Edge:  Attributes{"fontname": "Helvetica", "fontsize": "9", "color": "#57606a"},
+		NodeKinds: map[string]Attributes{
+			string(graph.NodeKindTarget):          {"shape": "box", "style": "rounded,filled", "fillcolor": "#eef4fb"},
+			string(graph.NodeKindExternalProduct): {"shape": "ellipse", "style": "dashed,filled", "fillcolor": "#f6f8fa", "color": "#8c959f"},
+		},
+		EdgeKinds: map[string]Attributes{
+			string(graph.EdgeKindProduct): {"style": "dashed", "color": "#8c959f"},
+			string(graph.EdgeKindByName):  {"style": "dotted"},
+		},
+		TargetTypes: map[string]Attributes{
+			string(graph.TargetTypeApp):           {"fillcolor": "#dcf2e3"},
+			string(graph.TargetTypeAppExtension):  {"fillcolor": "#edf8f0"},
+			string(graph.TargetTypeFramework):     {"fillcolor": "#dde9f7"},
+			string(graph.TargetTypeExecutable):    {"fillcolor": "#fff4d6"},
+			string(graph.TargetTypeTest):          {"fillcolor": "#fdecea"},
+			string(graph.TargetTypeUITest):        {"fillcolor": "#fbe3ef"},
+			string(graph.TargetTypeMacro):         {"fillcolor": "#efe7fb"},
+			string(graph.TargetTypePlugin):        {"fillcolor": "#fbeee0"},
+			string(graph.TargetTypeBinary):        {"fillcolor": "#eceff1"},
+			string(graph.TargetTypeSystemLibrary): {"fillcolor": "#f1f3f5"},
+			string(graph.TargetTypeAggregate):     {"fillcolor": "white"},
+		},
+	},
+	"dark": {
+		Graph: Attributes{"rankdir": "TB", "splines": "true", "ranksep": "0.6", "fontname": "Helvetica", "bgcolor": "#0d1117", "fontcolor": "#c9d1d9"},
+		Node:  Attributes{"fontname": "Helvetica", "fontsize": "11", "color": "#58a6ff", "fontcolor": "#c9d1d9"},
+		Edge:  Attributes{"fontname": "Helvetica", "fontsize": "9", "color": "#8b949e", "fontcolor": "#8b949e"},
+		NodeKinds: map[string]Attributes{
+			string(graph.NodeKindTarget):          {"shape": "box", "style": "rounded,filled", "fillcolor": "#161b22"},
+			string(graph.NodeKindExternalProduct): {"shape": "ellipse", "style": "dashed,filled", "fillcolor": "#0d1117", "color": "#6e7681"},
+		},
+		EdgeKinds: map[string]Attributes{
+			string(graph.EdgeKindProduct): {"style": "dashed", "color": "#6e7681"},
+			string(graph.EdgeKindByName):  {"style": "dotted"},
+		},
+		TargetTypes: map[string]Attributes{
+			string(graph.TargetTypeApp):           {"fillcolor": "#12361f"},
+			string(graph.TargetTypeAppExtension):  {"fillcolor": "#1a2f22"},
+			string(graph.TargetTypeFramework):     {"fillcolor": "#132a44"},
+			string(graph.TargetTypeExecutable):    {"fillcolor": "#3a2f0b"},
+			string(graph.TargetTypeTest):          {"fillcolor": "#3d1518"},
+			string(graph.TargetTypeUITest):        {"fillcolor": "#3b1630"},
+			string(graph.TargetTypeMacro):         {"fillcolor": "#2a1f47"},
+			string(graph.TargetTypePlugin):        {"fillcolor": "#3b2613"},
+			string(graph.TargetTypeBinary):        {"fillcolor": "#21262d"},
+			string(graph.TargetTypeSystemLibrary): {"fillcolor": "#1c2128"},
+			string(graph.TargetTypeAggregate):     {"fillcolor": "#0d1117"},
+		},
+	},
+	"print": {
+		Graph: Attributes{"rankdir": "TB", "splines": "true", "concentrate": "true", "fontname": "Times-Roman", "bgcolor": "white"},
+		Node:  Attributes{"fontname": "Times-Roman", "fontsize": "10", "color": "black", "fontcolor": "black"},
+		Edge:  Attributes{"color": "black", "fontname": "Times-Roman", "fontsize": "8"},
+		NodeKinds: map[string]Attributes{
+			string(graph.NodeKindTarget):          {"shape": "box"},
+			string(graph.NodeKindExternalProduct): {"shape": "ellipse", "style": "dashed"},
+		},
+		EdgeKinds: map[string]Attributes{
+			string(graph.EdgeKindProduct): {"style": "dashed"},
+			string(graph.EdgeKindByName):  {"style": "dotted"},
+		},
+	},
+}
+
+// Preset returns a copy of the named built-in theme.
+func Preset(name string) (Theme, bool) {
+	preset, ok := presets[name]
+	if !ok {
+		return Theme{}, false
+	}
+	return Default().merge(preset), true
+}
+
+// Resolve returns the default theme for an empty spec, the built-in preset with that
+// name, or the theme loaded from the file at spec.
+func Resolve(spec string) (Theme, error) {
+	if spec == "" {
+		return Default(), nil
+	}
+	if preset, ok := Preset(spec); ok {
+		return preset, nil
+	}
+	return Load(spec)
+}
+
+// Load reads and validates a theme JSON file. The theme is layered on top of its
+// Extends preset, or the default theme when Extends is empty.
+func Load(themePath string) (Theme, error) {
+	data, err := os.ReadFile(themePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Theme{}, apperrors.New(apperrors.KindInputNotFound, fmt.Sprintf("theme not found at %s (built-in presets: %s)", themePath, PresetUsage), err)
+		}
+		return Theme{}, apperrors.New(apperrors.KindRuntime, fmt.Sprintf("failed reading theme %s", themePath), err)
+	}
+	var t Theme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Theme{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("failed to decode theme %s", themePath), err)
+	}
+
+	base := Default()
+	if t.Extends != "" {
+		preset, ok := Preset(t.Extends)
+		if !ok {
+			return Theme{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("theme %s extends unknown preset %q (want %s)", themePath, t.Extends, PresetUsage), nil)
+		}
+		base = preset
+	}
+	for kind := range t.NodeKinds {
+		if kind != string(graph.NodeKindTarget) && kind != string(graph.NodeKindExternalProduct) {
+			return Theme{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("theme %s styles unknown node kind %q", themePath, kind), nil)
+		}
+	}
+	for targetType := range t.TargetTypes {
+		if !graph.IsTargetType(targetType) {
+			return Theme{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("theme %s styles unknown target type %q", themePath, targetType), nil)
+		}
+	}
+	for kind := range t.EdgeKinds {
+		switch graph.EdgeKind(kind) {
+		case graph.EdgeKindTarget, graph.EdgeKindProduct, graph.EdgeKindByName:
+		default:
+			return Theme{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("theme %s styles unknown edge kind %q", themePath, kind), nil)
+		}
+	}
+	for i := range t.Labels {
+		re, err := regexp.Compile(t.Labels[i].Pattern)
+		if err != nil {
+			return Theme{}, apperrors.New(apperrors.KindInvalidArgs, fmt.Sprintf("invalid label pattern %q in theme %s", t.Labels[i].Pattern, themePath), err)
+		}
+		t.Labels[i].re = re
+	}
+	merged := base.merge(t)
+	merged.Extends = t.Extends
+	return merged, nil
+}
+
+func mergeAttributes(base, overlay Attributes) Attributes {
+	if len(base) == 0 && len(overlay) == 0 {
+		return nil
+	}
+	merged := make(Attributes, len(base)+len(overlay))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overlay {
+		merged[key] = value
+	}
+	return merged
+}
+
+func mergeKinds(base, overlay map[string]Attributes) map[string]Attributes {
+	merged := make(map[string]Attributes, len(base)+len(overlay))
+	for kind, attrs := range base {
+		merged[kind] = mergeAttributes(attrs, nil)
+	}
+	for kind, attrs := range overlay {
+		merged[kind] = mergeAttributes(merged[kind], attrs)
+	}
+	return merged
+}
+
+// merge layers overlay on top of t, attribute by attribute. Label rules are appended.
+func (t Theme) merge(overlay Theme) Theme {
+	return Theme{
+		Graph:       mergeAttributes(t.Graph, overlay.Graph),
+		Node:        mergeAttributes(t.Node, overlay.Node),
+		Edge:        mergeAttributes(t.Edge, overlay.Edge),
+		NodeKinds:   mergeKinds(t.NodeKinds, overlay.NodeKinds),
+		EdgeKinds:   mergeKinds(t.EdgeKinds, overlay.EdgeKinds),
+		TargetTypes: mergeKinds(t.TargetTypes, overlay.TargetTypes),
+		Labels:      append(append([]LabelRule(nil), t.Labels...), overlay.Labels...),
+	}
+}
+
+func (r LabelRule) matches(label string) bool {
+	if r.re != nil {
+		return r.re.MatchString(label)
+	}
+	ok, err := regexp.MatchString(r.Pattern, label)
+	return err == nil && ok
+}
+
+// NodeAttributes returns the attributes for node: its kind style overridden by its
+// target type style and then by every label rule that matches, in order.
+func (t Theme) NodeAttributes(node graph.Node) Attributes {
+	attrs := mergeAttributes(nil, t.NodeKinds[string(node.Kind)])
+	if node.Kind == graph.NodeKindTarget && node.Type != "" {
+		attrs = mergeAttributes(attrs, t.TargetTypes[string(node.Type)])
+	}
+	for _, rule := range t.Labels {
+		if rule.matches(node.Label) {
+			attrs = mergeAttributes(attrs, rule.Attributes)
+		}
+	}
+	return attrs
+}
+
+// EdgeAttributes returns the attributes for edges of kind.
+func (t Theme) EdgeAttributes(kind graph.EdgeKind) Attributes {
+	return t.EdgeKinds[string(kind)]
+}
+
+// Keys returns the attribute names in sorted order.
+func (a Attributes) Keys() []string {
+	keys := make([]string, 0, len(a))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
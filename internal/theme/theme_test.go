@@ -0,0 +1,117 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	apperrors "swift-deps-diagram/internal/errors"
+	"swift-deps-diagram/internal/graph"
+)
+
+func writeTheme(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "theme.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write theme: %v", err)
+	}
+	return path
+}
+
+func TestResolveEmptyReturnsDefault(t *testing.T) {
+	got, err := Resolve("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Graph["rankdir"] != "TB" || got.NodeKinds["target"]["shape"] != "box" || got.NodeKinds["external_product"]["style"] != "dashed" {
+		t.Fatalf("unexpected default theme: %#v", got)
+	}
+}
+
+func TestResolvePresetsStyleEdgeKinds(t *testing.T) {
+	for _, name := range []string{"light", "dark", "print"} {
+		got, err := Resolve(name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", name, err)
+		}
+		if got.EdgeAttributes(graph.EdgeKindByName)["style"] != "dotted" {
+			t.Fatalf("expected dotted by_name edges in %s, got %#v", name, got.EdgeKinds)
+		}
+		if got.Graph["rankdir"] == "" {
+			t.Fatalf("expected rankdir in %s", name)
+		}
+	}
+}
+
+func TestLoadLayersFileOverPresetAndMatchesLabels(t *testing.T) {
+	path := writeTheme(t, `{
+		"extends": "dark",
+		"graph": {"rankdir": "LR", "splines": "ortho"},
+		"node_kinds": {"target": {"shape": "component"}},
+		"edge_kinds": {"target": {"color": "red"}},
+		"labels": [
+			{"pattern": "Tests$", "attributes": {"fillcolor": "purple"}},
+			{"pattern": "^Core", "attributes": {"penwidth": "2"}}
+		]
+	}`)
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Graph["rankdir"] != "LR" || got.Graph["splines"] != "ortho" || got.Graph["bgcolor"] != "#0d1117" {
+		t.Fatalf("expected file graph attributes over dark preset, got %#v", got.Graph)
+	}
+
+	attrs := got.NodeAttributes(graph.Node{Label: "CoreTests", Kind: graph.NodeKindTarget})
+	if attrs["shape"] != "component" || attrs["style"] != "rounded,filled" || attrs["fillcolor"] != "purple" || attrs["penwidth"] != "2" {
+		t.Fatalf("unexpected merged node attributes: %#v", attrs)
+	}
+	if got.EdgeAttributes(graph.EdgeKindTarget)["color"] != "red" || got.EdgeAttributes(graph.EdgeKindProduct)["style"] != "dashed" {
+		t.Fatalf("unexpected edge attributes: %#v", got.EdgeKinds)
+	}
+
+	dark, _ := Preset("dark")
+	if dark.NodeKinds["target"]["shape"] != "box" {
+		t.Fatalf("expected loading a theme to leave the preset untouched, got %#v", dark.NodeKinds)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); !apperrors.IsKind(err, apperrors.KindInputNotFound) {
+		t.Fatalf("expected input not found kind, got %v", err)
+	}
+	for _, content := range []string{
+		`{`,
+		`{"extends": "neon"}`,
+		`{"node_kinds": {"module": {"shape": "box"}}}`,
+		`{"edge_kinds": {"weak": {"style": "dotted"}}}`,
+		`{"target_types": {"application": {"shape": "box3d"}}}`,
+		`{"labels": [{"pattern": "(", "attributes": {}}]}`,
+	} {
+		if _, err := Load(writeTheme(t, content)); !apperrors.IsKind(err, apperrors.KindInvalidArgs) {
+			t.Fatalf("expected invalid args kind for %s, got %v", content, err)
+		}
+	}
+}
+
+func TestNodeAttributesLayersTargetTypes(t *testing.T) {
+	path := writeTheme(t, `{"extends": "light", "target_types": {"app": {"color": "#2da44e"}}, "labels": [{"pattern": "^Legacy", "attributes": {"shape": "box"}}]}`)
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	app := got.NodeAttributes(graph.Node{Label: "App", Kind: graph.NodeKindTarget, Type: graph.TargetTypeApp})
+	if app["shape"] != "box3d" || app["fillcolor"] != "#dcf2e3" || app["color"] != "#2da44e" {
+		t.Fatalf("expected default shape, preset fill and file color for apps, got %#v", app)
+	}
+	legacy := got.NodeAttributes(graph.Node{Label: "LegacyApp", Kind: graph.NodeKindTarget, Type: graph.TargetTypeApp})
+	if legacy["shape"] != "box" {
+		t.Fatalf("expected label rules to override target types, got %#v", legacy)
+	}
+	library := got.NodeAttributes(graph.Node{Label: "Core", Kind: graph.NodeKindTarget, Type: graph.TargetTypeLibrary})
+	if library["shape"] != "box" || library["fillcolor"] != got.NodeKinds["target"]["fillcolor"] {
+		t.Fatalf("expected libraries to keep the target style, got %#v", library)
+	}
+}
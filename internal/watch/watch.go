@@ -0,0 +1,162 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"swift-deps-diagram/internal/inputresolve"
+)
+
+// Config controls how often inputs are polled and how long they must stay unchanged
+// before a change is reported.
+type Config struct {
+	Interval time.Duration
+	Debounce time.Duration
+}
+
+// Stamp identifies one version of a file.
+type Stamp struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Snapshot maps existing input files to their stamps.
+type Snapshot map[string]Stamp
+
+// bazelFiles are the Bazel file names whose edits change the build graph.
+var bazelFiles = map[string]bool{
+	"WORKSPACE":       true,
+	"WORKSPACE.bazel": true,
+	"MODULE.bazel":    true,
+	"BUILD":           true,
+	"BUILD.bazel":     true,
+}
+
+// Files lists the manifests that define the graph of a resolved input: Package.swift
+// for SwiftPM, project.pbxproj and contents.xcworkspacedata for Xcode, Project.swift
+// and Workspace.swift for Tuist, and the WORKSPACE, MODULE.bazel, BUILD, and .bzl files
+// of a Bazel workspace. Files that do not exist yet are included so that creating them
+// counts as a change.
+func Files(resolved inputresolve.Resolved) []string {
+	var files []string
+	switch resolved.Mode {
+	case inputresolve.ModeSPM:
+		dir := resolved.PackagePath
+		if filepath.Ext(dir) == ".swift" {
+			dir = filepath.Dir(dir)
+		}
+		files = append(files, filepath.Join(dir, "Package.swift"))
+		versioned, _ := filepath.Glob(filepath.Join(dir, "Package@swift-*.swift"))
+		files = append(files, versioned...)
+	case inputresolve.ModeXcode:
+		if resolved.TuistPath != "" {
+			files = append(files, filepath.Join(resolved.TuistPath, "Project.swift"), filepath.Join(resolved.TuistPath, "Workspace.swift"))
+		}
+		if resolved.ProjectPath != "" {
+			files = append(files, filepath.Join(resolved.ProjectPath, "project.pbxproj"))
+		}
+		if resolved.WorkspacePath != "" {
+			files = append(files, filepath.Join(resolved.WorkspacePath, "contents.xcworkspacedata"))
+		}
+	case inputresolve.ModeBazel:
+		files = append(files, bazelWorkspaceFiles(resolved.BazelWorkspacePath)...)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// bazelWorkspaceFiles walks root for Bazel manifests, skipping hidden directories and
+// the bazel-* output symlinks.
+func bazelWorkspaceFiles(root string) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "bazel-")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if bazelFiles[name] || filepath.Ext(name) == ".bzl" {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// Take stats every path; missing files are left out of the snapshot.
+func Take(paths []string) Snapshot {
+	snapshot := make(Snapshot, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		snapshot[path] = Stamp{ModTime: info.ModTime(), Size: info.Size()}
+	}
+	return snapshot
+}
+
+// Changed returns the sorted paths added, removed, or modified between two snapshots.
+func Changed(before, after Snapshot) []string {
+	changed := make([]string, 0)
+	for path, stamp := range after {
+		if previous, ok := before[path]; !ok || !previous.ModTime.Equal(stamp.ModTime) || previous.Size != stamp.Size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Loop polls the files returned by list every cfg.Interval until ctx is done. Once a
+// change has been seen and the files then stay unchanged for cfg.Debounce, onChange is
+// called with every path that changed since the previous call.
+func Loop(ctx context.Context, cfg Config, list func() []string, onChange func(changed []string)) error {
+	current := Take(list())
+	pending := make(map[string]struct{})
+	var lastChange time.Time
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			next := Take(list())
+			if changed := Changed(current, next); len(changed) > 0 {
+				for _, path := range changed {
+					pending[path] = struct{}{}
+				}
+				current = next
+				lastChange = now
+				continue
+			}
+			if len(pending) == 0 || now.Sub(lastChange) < cfg.Debounce {
+				continue
+			}
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]struct{})
+			onChange(paths)
+		}
+	}
+}
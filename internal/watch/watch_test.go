@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"swift-deps-diagram/internal/inputresolve"
+)
+
+func touch(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestFilesListsManifestsPerMode(t *testing.T) {
+	dir := t.TempDir()
+	touch(t, filepath.Join(dir, "Package@swift-5.9.swift"), "")
+
+	got := Files(inputresolve.Resolved{Mode: inputresolve.ModeSPM, PackagePath: dir})
+	want := []string{filepath.Join(dir, "Package.swift"), filepath.Join(dir, "Package@swift-5.9.swift")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected spm files %v", got)
+	}
+
+	got = Files(inputresolve.Resolved{Mode: inputresolve.ModeXcode, ProjectPath: "App.xcodeproj", WorkspacePath: "App.xcworkspace"})
+	want = []string{filepath.Join("App.xcodeproj", "project.pbxproj"), filepath.Join("App.xcworkspace", "contents.xcworkspacedata")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected xcode files %v", got)
+	}
+
+	got = Files(inputresolve.Resolved{Mode: inputresolve.ModeXcode, TuistPath: "Tuist"})
+	want = []string{filepath.Join("Tuist", "Project.swift"), filepath.Join("Tuist", "Workspace.swift")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected tuist files %v", got)
+	}
+}
+
+func TestFilesWalksBazelWorkspace(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"MODULE.bazel", "BUILD.bazel", "app/BUILD", "tools/defs.bzl", "app/main.swift", ".git/BUILD", "bazel-out/BUILD"} {
+		touch(t, filepath.Join(root, name), "")
+	}
+
+	got := Files(inputresolve.Resolved{Mode: inputresolve.ModeBazel, BazelWorkspacePath: root})
+	want := []string{
+		filepath.Join(root, "BUILD.bazel"),
+		filepath.Join(root, "MODULE.bazel"),
+		filepath.Join(root, "app", "BUILD"),
+		filepath.Join(root, "tools", "defs.bzl"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected bazel files %v", got)
+	}
+}
+
+func TestChangedReportsAddedRemovedAndModifiedFiles(t *testing.T) {
+	at := time.Unix(100, 0)
+	before := Snapshot{"a": {ModTime: at, Size: 1}, "b": {ModTime: at, Size: 1}, "c": {ModTime: at, Size: 1}}
+	after := Snapshot{"a": {ModTime: at, Size: 1}, "b": {ModTime: at.Add(time.Second), Size: 1}, "d": {ModTime: at, Size: 1}}
+	if got := Changed(before, after); !reflect.DeepEqual(got, []string{"b", "c", "d"}) {
+		t.Fatalf("unexpected changes %v", got)
+	}
+	if got := Changed(after, after); len(got) != 0 {
+		t.Fatalf("expected no changes, got %v", got)
+	}
+}
+
+func TestLoopReportsDebouncedChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Package.swift")
+	touch(t, path, "v1")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []string, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- Loop(ctx, Config{Interval: 5 * time.Millisecond, Debounce: 20 * time.Millisecond}, func() []string { return []string{path} }, func(changed []string) {
+			changes <- changed
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	touch(t, path, "version 2")
+	if err := os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	select {
+	case got := <-changes:
+		if !reflect.DeepEqual(got, []string{path}) {
+			t.Fatalf("unexpected changes %v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected loop error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("loop did not stop after cancel")
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected one debounced change, got extra %v", <-changes)
+	}
+}
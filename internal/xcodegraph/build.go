@@ -9,10 +9,55 @@ import (
 	"swift-deps-diagram/internal/xcodeproj"
 )
 
-func isTestTarget(productType string) bool {
+func isTestProductType(productType string) bool {
 	return strings.Contains(productType, "unit-test") || strings.Contains(productType, "ui-testing")
 }
 
+// isTestTarget reports whether target is test code: a test bundle by product type or
+// test host, or a test-support target.
+func isTestTarget(target xcodeproj.Target) bool {
+	return isTestProductType(target.ProductType) || target.TestHost != "" || target.TestSupport
+}
+
+// targetType returns the normalized type of target. Bundles with a test host are tests
+// whatever their product type.
+func targetType(target xcodeproj.Target) graph.TargetType {
+	targetType := productTargetType(target.ProductType)
+	if target.TestHost != "" && targetType != graph.TargetTypeUITest {
+		return graph.TargetTypeTest
+	}
+	return targetType
+}
+
+// productTargetType maps an Xcode product type to a graph.TargetType. Aggregate and
+// legacy targets have no product type.
+func productTargetType(productType string) graph.TargetType {
+	const prefix = "com.apple.product-type."
+	kind := strings.TrimPrefix(productType, prefix)
+	switch {
+	case productType == "":
+		return graph.TargetTypeAggregate
+	case strings.Contains(kind, "ui-testing"):
+		return graph.TargetTypeUITest
+	case strings.Contains(kind, "unit-test"):
+		return graph.TargetTypeTest
+	case kind == "application" || strings.HasPrefix(kind, "application."):
+		return graph.TargetTypeApp
+	case strings.Contains(kind, "extension"):
+		return graph.TargetTypeAppExtension
+	case kind == "framework" || strings.HasPrefix(kind, "framework.") || kind == "xcframework":
+		return graph.TargetTypeFramework
+	case strings.HasPrefix(kind, "library.") || kind == "metal-library":
+		return graph.TargetTypeLibrary
+	case kind == "tool":
+		return graph.TargetTypeExecutable
+	case kind == "macro":
+		return graph.TargetTypeMacro
+	default:
+		return ""
+	}
+}
+
 func targetNodeID(name, id string, seen map[string]struct{}) string {
 	base := "target::" + name
 	if _, ok := seen[base]; !ok {
@@ -50,13 +95,20 @@ func Build(project xcodeproj.Project, includeTests bool) (graph.Graph, error) {
 		if target.Name == "" {
 			continue
 		}
-		if !includeTests && isTestTarget(target.ProductType) {
+		if !includeTests && isTestTarget(target) {
 			continue
 		}
 		nodeID := targetNodeID(target.Name, target.ID, nodeIDSeen)
 		nodeIDSeen[nodeID] = struct{}{}
 		targetIDToNodeID[target.ID] = nodeID
-		nodes[nodeID] = graph.Node{ID: nodeID, Label: target.Name, Kind: graph.NodeKindTarget}
+		nodes[nodeID] = graph.Node{ID: nodeID, Label: target.Name, Kind: graph.NodeKindTarget, Project: project.Name, Path: target.Path, Test: isTestTarget(target), NativeType: target.ProductType, Type: targetType(target)}
+	}
+
+	hostNodeIDs := make(map[string]string)
+	for _, target := range targets {
+		if nodeID, ok := targetIDToNodeID[target.ID]; ok && hostNodeIDs[target.Name] == "" {
+			hostNodeIDs[target.Name] = nodeID
+		}
 	}
 
 	for _, target := range targets {
@@ -65,11 +117,16 @@ func Build(project xcodeproj.Project, includeTests bool) (graph.Graph, error) {
 			continue
 		}
 
+		depNodeIDs := make([]string, 0, len(target.TargetDependsOn)+1)
 		for _, depTargetID := range target.TargetDependsOn {
-			toID, exists := targetIDToNodeID[depTargetID]
-			if !exists {
-				continue
+			if toID, exists := targetIDToNodeID[depTargetID]; exists {
+				depNodeIDs = append(depNodeIDs, toID)
 			}
+		}
+		if hostID, ok := hostNodeIDs[target.TestHost]; ok && hostID != fromID {
+			depNodeIDs = append(depNodeIDs, hostID)
+		}
+		for _, toID := range depNodeIDs {
 			edge := graph.Edge{FromID: fromID, ToID: toID, Kind: graph.EdgeKindTarget}
 			key := graph.EdgeKey(edge)
 			if _, ok := edgeDedup[key]; ok {
@@ -85,7 +142,7 @@ func Build(project xcodeproj.Project, includeTests bool) (graph.Graph, error) {
 			}
 			productID := productNodeID(product.PackageIdentity, product.Name)
 			if _, ok := nodes[productID]; !ok {
-				nodes[productID] = graph.Node{ID: productID, Label: product.Name, Kind: graph.NodeKindExternalProduct}
+				nodes[productID] = graph.Node{ID: productID, Label: product.Name, Kind: graph.NodeKindExternalProduct, Package: product.PackageIdentity}
 			}
 			edge := graph.Edge{FromID: fromID, ToID: productID, Kind: graph.EdgeKindProduct}
 			key := graph.EdgeKey(edge)
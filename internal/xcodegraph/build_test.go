@@ -35,8 +35,10 @@ func TestBuildCreatesTargetAndProductEdges(t *testing.T) {
 	if _, ok := g.Nodes["target::Core"]; !ok {
 		t.Fatal("missing Core target node")
 	}
-	if _, ok := g.Nodes["pkg::alamofire::Alamofire"]; !ok {
+	if product, ok := g.Nodes["pkg::alamofire::Alamofire"]; !ok {
 		t.Fatal("missing product node")
+	} else if product.Package != "alamofire" {
+		t.Fatalf("expected product package alamofire, got %q", product.Package)
 	}
 
 	targetEdgeFound := false
@@ -75,7 +77,72 @@ func TestBuildRespectsIncludeTests(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected build error: %v", err)
 	}
-	if _, ok := withTests.Nodes["target::AppTests"]; !ok {
+	if node, ok := withTests.Nodes["target::AppTests"]; !ok {
 		t.Fatal("expected test target when includeTests=true")
+	} else if !node.Test || withTests.Nodes["target::App"].Test {
+		t.Fatal("expected only AppTests marked as test")
+	} else if node.NativeType != "com.apple.product-type.bundle.unit-test" {
+		t.Fatalf("expected product type as native type, got %q", node.NativeType)
+	}
+}
+
+func TestBuildDetectsTestHostsAndTestSupport(t *testing.T) {
+	project := xcodeproj.Project{Targets: []xcodeproj.Target{
+		{ID: "T_APP", Name: "App", ProductType: "com.apple.product-type.application", TargetDependsOn: []string{"T_CORE"}},
+		{ID: "T_CORE", Name: "Core", ProductType: "com.apple.product-type.framework"},
+		{ID: "T_MOCKS", Name: "CoreMocks", ProductType: "com.apple.product-type.framework", TargetDependsOn: []string{"T_CORE"}, TestSupport: true},
+		{ID: "T_LEGACY", Name: "LegacyTests", ProductType: "com.apple.product-type.bundle", TargetDependsOn: []string{"T_MOCKS"}, TestHost: "App"},
+	}}
+
+	withoutTests, err := Build(project, false)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if len(withoutTests.Nodes) != 2 || len(withoutTests.Edges) != 1 {
+		t.Fatalf("expected only App and Core without tests, got %#v", withoutTests)
+	}
+
+	withTests, err := Build(project, true)
+	if err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	legacy := withTests.Nodes["target::LegacyTests"]
+	if !legacy.Test || legacy.Type != graph.TargetTypeTest {
+		t.Fatalf("expected hosted bundle marked as test, got %#v", legacy)
+	}
+	mocks := withTests.Nodes["target::CoreMocks"]
+	if !mocks.Test || mocks.Type != graph.TargetTypeFramework {
+		t.Fatalf("expected test-support framework marked as test, got %#v", mocks)
+	}
+	hostEdge := graph.Edge{FromID: "target::LegacyTests", ToID: "target::App", Kind: graph.EdgeKindTarget}
+	found := false
+	for _, edge := range withTests.Edges {
+		found = found || edge == hostEdge
+	}
+	if !found {
+		t.Fatalf("expected an edge to the test host, got %#v", withTests.Edges)
+	}
+}
+
+func TestProductTargetTypeNormalizesProductTypes(t *testing.T) {
+	cases := map[string]graph.TargetType{
+		"com.apple.product-type.application":                     graph.TargetTypeApp,
+		"com.apple.product-type.application.watchapp2-container": graph.TargetTypeApp,
+		"com.apple.product-type.app-extension":                   graph.TargetTypeAppExtension,
+		"com.apple.product-type.extensionkit-extension":          graph.TargetTypeAppExtension,
+		"com.apple.product-type.framework":                       graph.TargetTypeFramework,
+		"com.apple.product-type.framework.static":                graph.TargetTypeFramework,
+		"com.apple.product-type.library.static":                  graph.TargetTypeLibrary,
+		"com.apple.product-type.library.dynamic":                 graph.TargetTypeLibrary,
+		"com.apple.product-type.tool":                            graph.TargetTypeExecutable,
+		"com.apple.product-type.bundle.unit-test":                graph.TargetTypeTest,
+		"com.apple.product-type.bundle.ui-testing":               graph.TargetTypeUITest,
+		"":                              graph.TargetTypeAggregate,
+		"com.apple.product-type.bundle": "",
+	}
+	for productType, want := range cases {
+		if got := productTargetType(productType); got != want {
+			t.Fatalf("productTargetType(%q) = %q, want %q", productType, got, want)
+		}
 	}
 }
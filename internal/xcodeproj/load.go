@@ -17,15 +17,25 @@ import (
 )
 
 type Project struct {
+	// Name is the project file name without the .xcodeproj extension.
+	Name    string
 	Targets []Target
 }
 
 type Target struct {
-	ID              string
-	Name            string
-	ProductType     string
+	ID          string
+	Name        string
+	ProductType string
+	// Path is the first file-system synchronized group of the target, when present.
+	Path            string
 	TargetDependsOn []string
 	Products        []PackageProduct
+	// TestHost names the target a test bundle runs in, from its TEST_TARGET_NAME or
+	// TEST_HOST build setting; empty for other targets.
+	TestHost string
+	// TestSupport is set for targets that build with ENABLE_TESTING_SEARCH_PATHS, which
+	// Xcode requires for code that links XCTest outside a test bundle.
+	TestSupport bool
 }
 
 type PackageProduct struct {
@@ -86,7 +96,9 @@ func Load(ctx context.Context, xcodeprojPath string) (Project, error) {
 		return Project{}, apperrors.New(apperrors.KindXcodeParse, "failed to decode plutil JSON output", err)
 	}
 
-	return projectFromObjects(root.Objects), nil
+	project := projectFromObjects(root.Objects)
+	project.Name = strings.TrimSuffix(filepath.Base(xcodeprojPath), ".xcodeproj")
+	return project, nil
 }
 
 func projectFromObjects(objects map[string]map[string]interface{}) Project {
@@ -164,6 +176,19 @@ func projectFromObjects(objects map[string]map[string]interface{}) Project {
 			Name:        asString(obj["name"]),
 			ProductType: asString(obj["productType"]),
 		}
+		for _, groupID := range asStringSlice(obj["fileSystemSynchronizedGroups"]) {
+			if groupPath := asString(objects[groupID]["path"]); groupPath != "" {
+				t.Path = groupPath
+				break
+			}
+		}
+
+		settings := buildSettings(objects, asString(obj["buildConfigurationList"]))
+		t.TestHost = settings["TEST_TARGET_NAME"]
+		if t.TestHost == "" {
+			t.TestHost = testHostTarget(settings["TEST_HOST"])
+		}
+		t.TestSupport = settings["ENABLE_TESTING_SEARCH_PATHS"] == "YES"
 
 		for _, depID := range asStringSlice(obj["dependencies"]) {
 			if targetID, ok := targetDeps[depID]; ok && targetID != "" {
@@ -189,6 +214,32 @@ func projectFromObjects(objects map[string]map[string]interface{}) Project {
 	return Project{Targets: targets}
 }
 
+// buildSettings returns the string build settings of the configurations in listID. A
+// setting takes its value from the first configuration that sets it.
+func buildSettings(objects map[string]map[string]interface{}, listID string) map[string]string {
+	settings := make(map[string]string)
+	for _, configID := range asStringSlice(objects[listID]["buildConfigurations"]) {
+		values, _ := objects[configID]["buildSettings"].(map[string]interface{})
+		for key, value := range values {
+			if _, ok := settings[key]; !ok && asString(value) != "" {
+				settings[key] = asString(value)
+			}
+		}
+	}
+	return settings
+}
+
+// testHostTarget returns the application name in a TEST_HOST path such as
+// "$(BUILT_PRODUCTS_DIR)/App.app/App", which is the host target's product name.
+func testHostTarget(testHost string) string {
+	for _, part := range strings.Split(testHost, "/") {
+		if name, ok := strings.CutSuffix(part, ".app"); ok && name != "" && !strings.Contains(name, "$(") {
+			return name
+		}
+	}
+	return ""
+}
+
 func asString(v interface{}) string {
 	s, _ := v.(string)
 	return s
@@ -30,12 +30,47 @@ func TestLoadParsesTargetsAndProducts(t *testing.T) {
       "isa": "PBXNativeTarget",
       "name": "App",
       "dependencies": ["DEP_CORE"],
+      "fileSystemSynchronizedGroups": ["GROUP_APP"],
       "packageProductDependencies": ["PROD_ALAMOFIRE"]
     },
     "TARGET_CORE": {
       "isa": "PBXNativeTarget",
       "name": "Core"
     },
+    "TARGET_TESTS": {
+      "isa": "PBXNativeTarget",
+      "name": "AppTests",
+      "buildConfigurationList": "LIST_TESTS"
+    },
+    "TARGET_MOCKS": {
+      "isa": "PBXNativeTarget",
+      "name": "CoreMocks",
+      "buildConfigurationList": "LIST_MOCKS"
+    },
+    "LIST_TESTS": {
+      "isa": "XCConfigurationList",
+      "buildConfigurations": ["CONFIG_TESTS_DEBUG", "CONFIG_TESTS_RELEASE"]
+    },
+    "CONFIG_TESTS_DEBUG": {
+      "isa": "XCBuildConfiguration",
+      "buildSettings": {"BUNDLE_LOADER": "$(TEST_HOST)"}
+    },
+    "CONFIG_TESTS_RELEASE": {
+      "isa": "XCBuildConfiguration",
+      "buildSettings": {"TEST_HOST": "$(BUILT_PRODUCTS_DIR)/App.app/$(BUNDLE_EXECUTABLE_FOLDER_PATH)/App"}
+    },
+    "LIST_MOCKS": {
+      "isa": "XCConfigurationList",
+      "buildConfigurations": ["CONFIG_MOCKS"]
+    },
+    "CONFIG_MOCKS": {
+      "isa": "XCBuildConfiguration",
+      "buildSettings": {"ENABLE_TESTING_SEARCH_PATHS": "YES"}
+    },
+    "GROUP_APP": {
+      "isa": "PBXFileSystemSynchronizedRootGroup",
+      "path": "App"
+    },
     "DEP_CORE": {
       "isa": "PBXTargetDependency",
       "target": "TARGET_CORE"
@@ -62,19 +97,33 @@ func TestLoadParsesTargetsAndProducts(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected load error: %v", err)
 	}
-	if len(project.Targets) != 2 {
-		t.Fatalf("expected 2 targets, got %d", len(project.Targets))
+	if project.Name != "App" {
+		t.Fatalf("expected project name App, got %q", project.Name)
+	}
+	if len(project.Targets) != 4 {
+		t.Fatalf("expected 4 targets, got %d", len(project.Targets))
 	}
 
-	var appTarget Target
+	byName := map[string]Target{}
 	for _, target := range project.Targets {
-		if target.Name == "App" {
-			appTarget = target
-		}
+		byName[target.Name] = target
+	}
+	appTarget := byName["App"]
+	if appTarget.TestHost != "" || appTarget.TestSupport {
+		t.Fatalf("did not expect App to be test code: %#v", appTarget)
+	}
+	if tests := byName["AppTests"]; tests.TestHost != "App" || tests.TestSupport {
+		t.Fatalf("expected the App test host from TEST_HOST, got %#v", tests)
+	}
+	if mocks := byName["CoreMocks"]; mocks.TestHost != "" || !mocks.TestSupport {
+		t.Fatalf("expected CoreMocks marked as test support, got %#v", mocks)
 	}
 	if appTarget.Name != "App" {
 		t.Fatal("expected App target")
 	}
+	if appTarget.Path != "App" {
+		t.Fatalf("expected synchronized group path App, got %q", appTarget.Path)
+	}
 	if len(appTarget.TargetDependsOn) != 1 || appTarget.TargetDependsOn[0] != "TARGET_CORE" {
 		t.Fatalf("unexpected target deps: %#v", appTarget.TargetDependsOn)
 	}